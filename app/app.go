@@ -3,16 +3,22 @@ package app
 import (
 	"context"
 	"fmt"
+	"log/slog"
 
 	"snipgo/internal/core"
+	"snipgo/internal/gitsync"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
+// SnippetsChangedEvent is the runtime event emitted when snippets change on disk
+const SnippetsChangedEvent = "snippets:changed"
+
 // App struct
 type App struct {
-	ctx     context.Context
-	manager *core.Manager
+	ctx         context.Context
+	manager     *core.Manager
+	stopWatcher context.CancelFunc
 }
 
 // NewApp creates a new App application struct
@@ -22,6 +28,11 @@ func NewApp() (*App, error) {
 		return nil, fmt.Errorf("failed to create manager: %w", err)
 	}
 
+	// Commit changes when the data directory is a git repository
+	if repo, err := gitsync.Open(manager.Storage().GetSnippetsDir()); err == nil {
+		manager.SetCommitter(repo)
+	}
+
 	app := &App{
 		manager: manager,
 	}
@@ -38,6 +49,25 @@ func NewApp() (*App, error) {
 // so we can call the runtime methods
 func (a *App) OnStartup(ctx context.Context) {
 	a.ctx = ctx
+
+	// Watch the snippets directory and push changes to the frontend
+	watchCtx, cancel := context.WithCancel(ctx)
+	a.stopWatcher = cancel
+	go func() {
+		err := a.manager.Watch(watchCtx, core.DefaultWatchDebounce, func(ev core.ChangeEvent) {
+			runtime.EventsEmit(a.ctx, SnippetsChangedEvent, ev)
+		})
+		if err != nil {
+			slog.Error("file watcher stopped", "error", err)
+		}
+	}()
+}
+
+// OnShutdown is called when the app is closing
+func (a *App) OnShutdown(ctx context.Context) {
+	if a.stopWatcher != nil {
+		a.stopWatcher()
+	}
 }
 
 // GetAllSnippets returns all snippets
@@ -50,24 +80,92 @@ func (a *App) GetSnippet(id string) (*core.Snippet, error) {
 	return a.manager.GetByID(id)
 }
 
-// SaveSnippet saves a snippet
-func (a *App) SaveSnippet(snippet *core.Snippet) error {
-	return a.manager.Save(snippet)
+// SaveSnippet saves a snippet and returns it with its new revision.
+// If the file changed on disk since the snippet was loaded, the error
+// message starts with "conflict:" and ResolveSnippetConflict can be used.
+func (a *App) SaveSnippet(snippet *core.Snippet) (*core.Snippet, error) {
+	if err := a.manager.Save(snippet); err != nil {
+		return nil, err
+	}
+	return snippet, nil
 }
 
-// DeleteSnippet deletes a snippet by ID
+// ConflictResolution is the outcome of ResolveSnippetConflict
+type ConflictResolution struct {
+	Snippet   *core.Snippet `json:"snippet"`
+	Conflicts []string      `json:"conflicts"` // fields left to resolve by hand
+	Saved     bool          `json:"saved"`
+}
+
+// ResolveSnippetConflict settles a save conflict with resolution "mine",
+// "theirs" or "merge". A merge with conflicts is returned unsaved, with
+// conflict markers in the body, for the user to finish and save.
+func (a *App) ResolveSnippetConflict(snippet *core.Snippet, resolution string) (*ConflictResolution, error) {
+	r, err := core.ParseResolution(resolution)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, conflicts, err := a.manager.ResolveConflict(snippet, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConflictResolution{
+		Snippet:   resolved,
+		Conflicts: conflicts,
+		Saved:     len(conflicts) == 0,
+	}, nil
+}
+
+// DeleteSnippet moves a snippet to the trash by ID
 func (a *App) DeleteSnippet(id string) error {
 	return a.manager.Delete(id)
 }
 
-// SearchSnippets searches snippets by query
-func (a *App) SearchSnippets(query string) ([]*core.Snippet, error) {
-	results := a.manager.Search(query)
-	snippets := make([]*core.Snippet, len(results))
-	for i, result := range results {
-		snippets[i] = result.Snippet
-	}
-	return snippets, nil
+// ListTrash returns the deleted snippets in the trash, most recent first
+func (a *App) ListTrash() ([]*core.TrashEntry, error) {
+	return a.manager.ListTrash()
+}
+
+// RestoreSnippet moves a deleted snippet out of the trash by ID, undoing
+// DeleteSnippet
+func (a *App) RestoreSnippet(id string) (*core.Snippet, error) {
+	return a.manager.Restore(id)
+}
+
+// EmptyTrash permanently deletes the snippets in the trash and returns how
+// many there were
+func (a *App) EmptyTrash() (int, error) {
+	return a.manager.EmptyTrash()
+}
+
+// GetSnippetHistory returns the previous versions of a snippet, most recent
+// first
+func (a *App) GetSnippetHistory(id string) ([]*core.Version, error) {
+	return a.manager.History(id)
+}
+
+// RevertSnippet saves a previous version of a snippet, given its rev, as
+// the current version
+func (a *App) RevertSnippet(id, rev string) (*core.Snippet, error) {
+	return a.manager.Revert(id, rev)
+}
+
+// SearchSnippets searches snippets by query, returning the ranked results
+// with match ranges and body excerpts, or an error for a malformed query
+func (a *App) SearchSnippets(query string) ([]*core.SearchResult, error) {
+	return a.manager.SearchQuery(query)
+}
+
+// GetSnippetParams returns the placeholders in a snippet body
+func (a *App) GetSnippetParams(body string) []core.Param {
+	return core.ExtractParams(body)
+}
+
+// RenderSnippet substitutes placeholder values into a snippet body
+func (a *App) RenderSnippet(body string, values map[string]string) (string, error) {
+	return core.RenderParams(body, values)
 }
 
 // CopyToClipboard copies text to clipboard
@@ -2,6 +2,10 @@ package main
 
 import (
 	"os"
+	"sort"
+	"strings"
+
+	"snipgo/internal/core"
 
 	"github.com/spf13/cobra"
 )
@@ -9,17 +13,17 @@ import (
 var completionCmd = &cobra.Command{
 	Use:   "completion",
 	Short: "Generate completion script",
-	Long: `Generate shell completion scripts for snipgo.
+	Long: `Generate shell completion scripts for snipgo, for bash, zsh, fish and
+PowerShell. Besides commands and flags, they complete snippet titles and ID
+prefixes (copy, edit, exec, show, rm, history, ...) and existing tags.
 
 To load completions in your current shell session:
+  source <(snipgo completion bash)
   source <(snipgo completion zsh)
+  snipgo completion fish | source
+  snipgo completion powershell | Out-String | Invoke-Expression
 
-To load completions for every new session, execute once:
-  # Linux:
-  snipgo completion zsh > "${fpath[1]}/_snipgo"
-  
-  # macOS:
-  snipgo completion zsh > $(brew --prefix)/share/zsh/site-functions/_snipgo
+See "snipgo completion <shell> --help" to load them for every new session.
 `,
 	Args: cobra.NoArgs,
 }
@@ -48,4 +52,142 @@ func runCompletionZsh(cmd *cobra.Command, args []string) error {
 	return rootCmd.GenZshCompletion(os.Stdout)
 }
 
+var completionBashCmd = &cobra.Command{
+	Use:   "bash",
+	Short: "Generate bash completion script",
+	Long: `Generate the autocompletion script for bash. It needs the bash-completion
+package.
+
+To load completions in your current shell session:
+  source <(snipgo completion bash)
+
+To load completions for every new session, execute once:
+  # Linux:
+  snipgo completion bash > /etc/bash_completion.d/snipgo
+
+  # macOS:
+  snipgo completion bash > $(brew --prefix)/etc/bash_completion.d/snipgo
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return rootCmd.GenBashCompletionV2(os.Stdout, true)
+	},
+}
+
+var completionFishCmd = &cobra.Command{
+	Use:   "fish",
+	Short: "Generate fish completion script",
+	Long: `Generate the autocompletion script for fish.
+
+To load completions in your current shell session:
+  snipgo completion fish | source
+
+To load completions for every new session, execute once:
+  snipgo completion fish > ~/.config/fish/completions/snipgo.fish
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return rootCmd.GenFishCompletion(os.Stdout, true)
+	},
+}
+
+var completionPowerShellCmd = &cobra.Command{
+	Use:   "powershell",
+	Short: "Generate PowerShell completion script",
+	Long: `Generate the autocompletion script for PowerShell.
+
+To load completions in your current shell session:
+  snipgo completion powershell | Out-String | Invoke-Expression
+
+To load completions for every new session, add the output of the above
+command to your PowerShell profile.
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return rootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+	},
+}
+
+// completeSnippets completes a snippet reference with the titles of the
+// snippets, or with ID prefixes once what is typed starts like an ID
+func completeSnippets(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 || manager == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var completions []cobra.Completion
+	for _, snippet := range sortedSnippets() {
+		if toComplete != "" && strings.HasPrefix(strings.ToUpper(snippet.ID), strings.ToUpper(toComplete)) {
+			completions = append(completions, cobra.CompletionWithDesc(completionID(snippet.ID, toComplete), snippet.Title))
+		} else if strings.HasPrefix(strings.ToLower(snippet.Title), strings.ToLower(toComplete)) {
+			completions = append(completions, cobra.CompletionWithDesc(snippet.Title, shortID(snippet.ID)))
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
 
+// completeTags completes the last tag of a comma-separated --tags value with
+// the existing tags not in the list yet
+func completeTags(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if manager == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	listed := ""
+	current := toComplete
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		listed, current = toComplete[:i+1], toComplete[i+1:]
+	}
+	used := make(map[string]bool)
+	for _, tag := range strings.Split(listed, ",") {
+		used[strings.TrimSpace(tag)] = true
+	}
+
+	var completions []cobra.Completion
+	for _, tag := range manager.Tags() {
+		if !used[tag] && strings.HasPrefix(tag, current) {
+			completions = append(completions, listed+tag)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+// completeLanguages completes --language with the languages of the
+// existing snippets
+func completeLanguages(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if manager == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	seen := make(map[string]bool)
+	var completions []cobra.Completion
+	for _, snippet := range manager.GetAll() {
+		if language := snippet.Language; language != "" && !seen[language] && strings.HasPrefix(language, toComplete) {
+			seen[language] = true
+			completions = append(completions, language)
+		}
+	}
+	sort.Strings(completions)
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// sortedSnippets returns all snippets sorted by title
+func sortedSnippets() []*core.Snippet {
+	snippets := manager.GetAll()
+	sort.Slice(snippets, func(i, j int) bool {
+		if snippets[i].Title != snippets[j].Title {
+			return snippets[i].Title < snippets[j].Title
+		}
+		return snippets[i].ID < snippets[j].ID
+	})
+	return snippets
+}
+
+// completionID returns the ID to complete toComplete with: the short ID
+// shown by list, or the full ID once more than that is typed
+func completionID(id, toComplete string) string {
+	if len(toComplete) > len(shortID(id)) {
+		return id
+	}
+	return shortID(id)
+}
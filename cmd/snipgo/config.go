@@ -3,6 +3,9 @@ package main
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 
 	"snipgo/internal/config"
 
@@ -52,7 +55,7 @@ var configShowCmd = &cobra.Command{
 var configSetCmd = &cobra.Command{
 	Use:   "set [key] [value]",
 	Short: "Set a configuration value",
-	Long:  "Set a configuration value. Available keys: data_directory",
+	Long:  "Set a configuration value. Available keys: data_directory, picker (builtin or fzf), history_limit (versions kept per snippet, -1 to disable), exec_log (JSONL file exec records runs in, off to disable), interpreters.<language> (command line exec runs snippets of that language with, {file} standing for the body's file; empty to remove)",
 	Args:  cobra.ExactArgs(2),
 	RunE:  runConfigSet,
 }
@@ -86,6 +89,24 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 	fmt.Println("Current configuration:")
 	fmt.Printf("  Config File: %s\n", configPath)
 	fmt.Printf("  Data Directory: %s\n", cfg.DataDirectory)
+	fmt.Printf("  Picker: %s\n", cfg.Picker)
+	fmt.Printf("  History Limit: %d\n", cfg.HistoryLimit)
+	if path := cfg.ExecLogPath(); path != "" {
+		fmt.Printf("  Exec Log: %s\n", path)
+	} else {
+		fmt.Printf("  Exec Log: %s\n", config.ExecLogOff)
+	}
+	if len(cfg.Interpreters) > 0 {
+		fmt.Println("  Interpreters:")
+		languages := make([]string, 0, len(cfg.Interpreters))
+		for language := range cfg.Interpreters {
+			languages = append(languages, language)
+		}
+		sort.Strings(languages)
+		for _, language := range languages {
+			fmt.Printf("    %s: %s\n", language, cfg.Interpreters[language])
+		}
+	}
 
 	return nil
 }
@@ -102,8 +123,33 @@ func runConfigSet(cmd *cobra.Command, args []string) error {
 	switch key {
 	case "data_directory":
 		cfg.DataDirectory = value
+	case "picker":
+		if err := config.ValidatePicker(value); err != nil {
+			return err
+		}
+		cfg.Picker = value
+	case "history_limit":
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid history_limit %q: must be a number", value)
+		}
+		cfg.HistoryLimit = limit
+	case "exec_log":
+		cfg.ExecLog = value
 	default:
-		return fmt.Errorf("unknown configuration key: %s", key)
+		language, ok := strings.CutPrefix(key, "interpreters.")
+		if !ok || language == "" {
+			return fmt.Errorf("unknown configuration key: %s", key)
+		}
+		language = strings.ToLower(language)
+		if value == "" {
+			delete(cfg.Interpreters, language)
+			break
+		}
+		if cfg.Interpreters == nil {
+			cfg.Interpreters = make(map[string]string)
+		}
+		cfg.Interpreters[language] = value
 	}
 
 	if err := config.SaveConfig(cfg); err != nil {
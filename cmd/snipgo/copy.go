@@ -8,29 +8,47 @@ import (
 )
 
 var copyCmd = &cobra.Command{
-	Use:   "copy [query]",
+	Use:   "copy [id | title | query...]",
 	Short: "Copy snippet body to clipboard",
-	Long:  "Searches for a snippet and copies its body to the clipboard",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runCopy,
+	Long: `Copies the body of a snippet to the clipboard.
+
+The snippet is named by its ID, an ID prefix, its exact title or a query with
+the same syntax as search. If the query matches several snippets, or there is
+no argument, you select one interactively.
+
+Placeholders such as <branch=main> or {{port:8080}} are prompted for before
+copying; use --var name=value to set them up front.`,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeSnippets,
+	RunE:              runCopy,
 }
 
-func runCopy(cmd *cobra.Command, args []string) error {
-	query := args[0]
-	results := manager.Search(query)
+var copyVars []string
+
+func init() {
+	copyCmd.Flags().StringArrayVar(&copyVars, "var", nil, "Set a placeholder value (name=value), may be repeated")
+}
 
-	if len(results) == 0 {
-		return fmt.Errorf("no snippets found for query: %s", query)
+func runCopy(cmd *cobra.Command, args []string) error {
+	snippet, err := resolveSnippet(args)
+	if err != nil {
+		return err
 	}
 
-	// Get the top result
-	topResult := results[0]
-	body := topResult.Snippet.Body
+	// Fill in placeholders
+	preset, err := parseVarFlags(copyVars)
+	if err != nil {
+		return err
+	}
+	body, _, err := fillParams(snippet, preset)
+	if err != nil {
+		return err
+	}
 
 	if err := clipboard.WriteAll(body); err != nil {
 		return fmt.Errorf("failed to copy to clipboard: %w", err)
 	}
 
-	fmt.Printf("Copied body of snippet '%s' to clipboard\n", topResult.Snippet.Title)
+	fmt.Printf("Copied body of snippet '%s' to clipboard\n", snippet.Title)
 	return nil
 }
@@ -1,96 +1,45 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"os"
-	"os/exec"
+	"io"
+	"strings"
 
+	"snipgo/internal/core"
+
+	"github.com/chzyer/readline"
 	"github.com/spf13/cobra"
 )
 
 var editCmd = &cobra.Command{
-	Use:   "edit",
+	Use:   "edit [id | title | query...]",
 	Short: "Edit a snippet",
-	Long:  "Interactively select a snippet using fzf and edit it with $EDITOR",
-	Args:  cobra.NoArgs,
-	RunE:  runEdit,
+	Long: `Edits a snippet with $EDITOR.
+
+The snippet is named by its ID, an ID prefix, its exact title or a search
+query. Without an argument, or when several snippets match, it is selected
+interactively.`,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeSnippets,
+	RunE:              runEdit,
 }
 
 func runEdit(cmd *cobra.Command, args []string) error {
-	// Get all snippets
-	snippets := manager.GetAll()
-	if len(snippets) == 0 {
-		return fmt.Errorf("no snippets found")
-	}
-
-	// Use fzf to select
-	selected, err := selectSnippetWithFzf(snippets)
+	selected, err := resolveSnippet(args)
 	if err != nil {
 		return err
 	}
 
-	// Get editor from environment variable, default to vi
-	editor := os.Getenv("EDITOR")
-	if editor == "" {
-		editor = "vi"
-	}
-
-	// Create temporary file
-	tmpFile, err := os.CreateTemp("", "snipgo-edit-*.md")
-	if err != nil {
-		return fmt.Errorf("failed to create temporary file: %w", err)
-	}
-	tmpPath := tmpFile.Name()
-	defer os.Remove(tmpPath) // Clean up temp file
-
 	// Serialize snippet to markdown
 	content, err := serializeSnippetForEdit(selected)
 	if err != nil {
 		return fmt.Errorf("failed to serialize snippet: %w", err)
 	}
 
-	// Write to temp file
-	if _, err := tmpFile.Write(content); err != nil {
-		tmpFile.Close()
-		return fmt.Errorf("failed to write to temporary file: %w", err)
-	}
-	if err := tmpFile.Close(); err != nil {
-		return fmt.Errorf("failed to close temporary file: %w", err)
-	}
-
-	// Get file modification time before editing
-	beforeStat, err := os.Stat(tmpPath)
+	editedContent, err := editInEditor(content)
 	if err != nil {
-		return fmt.Errorf("failed to stat temporary file: %w", err)
-	}
-	beforeModTime := beforeStat.ModTime()
-
-	// Open editor
-	editCmd := exec.Command(editor, tmpPath)
-	editCmd.Stdin = os.Stdin
-	editCmd.Stdout = os.Stdout
-	editCmd.Stderr = os.Stderr
-
-	if err := editCmd.Run(); err != nil {
-		return fmt.Errorf("editor exited with error: %w", err)
-	}
-
-	// Check if file was modified
-	afterStat, err := os.Stat(tmpPath)
-	if err != nil {
-		return fmt.Errorf("failed to stat temporary file after editing: %w", err)
-	}
-	afterModTime := afterStat.ModTime()
-
-	// If file wasn't modified, user might have cancelled
-	if beforeModTime.Equal(afterModTime) {
-		return fmt.Errorf("file was not modified, edit cancelled")
-	}
-
-	// Read edited content
-	editedContent, err := os.ReadFile(tmpPath)
-	if err != nil {
-		return fmt.Errorf("failed to read edited file: %w", err)
+		return err
 	}
 
 	// Parse edited content
@@ -108,14 +57,85 @@ func runEdit(cmd *cobra.Command, args []string) error {
 	editedSnippet.ID = selected.ID
 	// Preserve created_at timestamp
 	editedSnippet.CreatedAt = selected.CreatedAt
+	// Detect changes made to the file while the editor was open
+	editedSnippet.Revision = selected.Revision
 
 	// Save the edited snippet
-	if err := manager.Save(editedSnippet); err != nil {
+	err = manager.Save(editedSnippet)
+	var conflict core.ErrConflict
+	if errors.As(err, &conflict) {
+		err = resolveEditConflict(editedSnippet)
+	} else if err == nil {
+		fmt.Printf("Snippet '%s' updated successfully\n", editedSnippet.Title)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to save edited snippet: %w", err)
 	}
-
-	fmt.Printf("Snippet '%s' updated successfully\n", editedSnippet.Title)
 	return nil
 }
 
+// resolveEditConflict asks the user how to settle a conflict between their
+// edit and a concurrent change on disk, saves the result and reports it
+func resolveEditConflict(mine *core.Snippet) error {
+	fmt.Printf("Snippet '%s' was modified on disk while you were editing.\n", mine.Title)
+
+	answer, err := readline.Line("Keep [m]ine, keep [t]heirs, [e] merge, or [a]bort? ")
+	if err != nil {
+		if err == io.EOF {
+			return fmt.Errorf("cancelled")
+		}
+		return fmt.Errorf("failed to read answer: %w", err)
+	}
 
+	var resolution core.Resolution
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "m", "mine":
+		resolution = core.ResolveKeepMine
+	case "t", "theirs":
+		resolution = core.ResolveKeepTheirs
+	case "e", "merge":
+		resolution = core.ResolveMerge
+	default:
+		return fmt.Errorf("edit aborted, snippet left unchanged")
+	}
+
+	resolved, conflicts, err := manager.ResolveConflict(mine, resolution)
+	if err != nil {
+		return err
+	}
+	switch {
+	case resolution == core.ResolveKeepMine:
+		fmt.Printf("Snippet '%s' updated, replacing the changes on disk\n", resolved.Title)
+		return nil
+	case resolution == core.ResolveKeepTheirs:
+		fmt.Printf("Kept the version of '%s' on disk, your edit was discarded\n", resolved.Title)
+		return nil
+	case len(conflicts) == 0:
+		fmt.Printf("Snippet '%s' updated, merged with the changes on disk\n", resolved.Title)
+		return nil
+	}
+
+	// Let the user finish the merge in the editor
+	fmt.Printf("Merge conflicts in: %s. Opening editor to resolve them.\n", strings.Join(conflicts, ", "))
+	content, err := serializeSnippetForEdit(resolved)
+	if err != nil {
+		return fmt.Errorf("failed to serialize snippet: %w", err)
+	}
+	editedContent, err := editInEditor(content)
+	if err != nil {
+		return err
+	}
+	merged, err := parseSnippetFromEdit(editedContent)
+	if err != nil {
+		return fmt.Errorf("failed to parse edited content: %w", err)
+	}
+	merged.ID = resolved.ID
+	merged.CreatedAt = resolved.CreatedAt
+	merged.Revision = resolved.Revision
+
+	if err := manager.Save(merged); err != nil {
+		return err
+	}
+	fmt.Printf("Snippet '%s' updated, merged with the changes on disk\n", merged.Title)
+	return nil
+}
@@ -1,45 +1,257 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
-	"os/exec"
+	"strings"
+	"time"
 
+	"snipgo/internal/config"
+	"snipgo/internal/core"
+	"snipgo/internal/runlog"
+	"snipgo/internal/runner"
+
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 )
 
 var execCmd = &cobra.Command{
-	Use:   "exec",
-	Short: "Execute a snippet interactively",
-	Long:  "Interactively select a snippet using fzf and execute its body as a shell command",
-	Args:  cobra.NoArgs,
-	RunE:  runExec,
+	Use:   "exec [id | title | query...]",
+	Short: "Execute a snippet",
+	Long: `Executes the body of a snippet with the interpreter of its language: sh
+(also without a language), bash, zsh, fish, python, node, ruby, perl,
+go (go run) or pwsh. Other languages, such as yaml or text, run with sh
+unless an interpreter is set for them in the configuration, where {file}
+stands for the file holding the body:
+
+  snipgo config set interpreters.sql "psql -d app -f {file}"
+
+Name the snippet by ID, ID prefix, exact title or search query, or leave it
+out to select it interactively, as when several snippets match.
+
+Placeholders such as <branch=main> or {{port:8080}} are prompted for before
+running; use --var name=value to set them up front.
+
+These optional frontmatter fields are honoured, and can be overridden with
+flags:
+
+  workdir: ~/src/app          directory to run in
+  env: {AWS_PROFILE: prod}    environment variables to set
+  timeout: 30s                stop the run after this long
+  interpreter: python3 -u     command line to run the body with
+  confirm: true               ask before running, even with --yes
+
+The body is shown and you are asked before it runs, unless --yes is given.
+Commands that destroy data or run code from the network, such as rm -rf /,
+dd of=/dev/..., curl ... | sh, DROP TABLE or git push --force, are flagged
+with a warning on stderr, also with --yes. --dry-run prints the body with
+its placeholders filled in, without running it. When stdin is not a
+terminal, --yes is required.
+
+Once the snippet has started, snipgo exits with its exit code: 128 plus the
+signal number if it was killed by a signal, 124 if it timed out. Before
+that, snipgo's own codes apply: 1 if no snippet matched, 2 for other errors,
+such as a declined confirmation. A snippet may exit with 1 or 2 too; when
+that matters, check that the snippet resolves first, e.g. with snipgo show.
+SIGTERM and SIGHUP sent to snipgo are passed on to the snippet.
+
+Each run is recorded in the run log, see "snipgo runs".`,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeSnippets,
+	RunE:              runExec,
+}
+
+var (
+	execVars        []string
+	execDir         string
+	execEnv         []string
+	execTimeout     string
+	execInterpreter string
+	execDryRun      bool
+	execYes         bool
+)
+
+func init() {
+	execCmd.Flags().StringArrayVar(&execVars, "var", nil, "Set a placeholder value (name=value), may be repeated")
+	execCmd.Flags().StringVarP(&execDir, "dir", "C", "", "Run in this directory instead of the snippet's workdir")
+	execCmd.Flags().StringArrayVar(&execEnv, "env", nil, "Set an environment variable (KEY=value), may be repeated")
+	execCmd.Flags().StringVar(&execTimeout, "timeout", "", "Stop the run after this long, e.g. 30s (0 for none)")
+	execCmd.Flags().StringVar(&execInterpreter, "interpreter", "", "Command line to run the body with, e.g. \"python3 -u\"")
+	execCmd.Flags().BoolVar(&execDryRun, "dry-run", false, "Print the command with its placeholders filled in instead of running it")
+	execCmd.Flags().BoolVarP(&execYes, "yes", "y", false, "Run without asking for confirmation, unless the snippet sets confirm: true")
+	execCmd.MarkFlagsMutuallyExclusive("dry-run", "yes")
+	execCmd.MarkFlagDirname("dir")
 }
 
 func runExec(cmd *cobra.Command, args []string) error {
-	// Get all snippets
-	snippets := manager.GetAll()
-	if len(snippets) == 0 {
-		return fmt.Errorf("no snippets found")
+	selected, err := resolveSnippet(args)
+	if err != nil {
+		return err
+	}
+
+	opts, err := execOptions(selected)
+	if err != nil {
+		return err
 	}
 
-	// Use fzf to select
-	selected, err := selectSnippetWithFzf(snippets)
+	// Fill in placeholders
+	preset, err := parseVarFlags(execVars)
+	if err != nil {
+		return err
+	}
+	body, vars, err := fillParams(selected, preset)
 	if err != nil {
 		return err
 	}
 
-	// Execute body as shell command
-	execCmd := exec.Command("sh", "-c", selected.Body)
-	execCmd.Stdin = os.Stdin
-	execCmd.Stdout = os.Stdout
-	execCmd.Stderr = os.Stderr
+	return execBody(cmd, selected, body, vars, opts)
+}
+
+// execBody runs the rendered body of a snippet, once confirmed, records the
+// run in the run log and passes on its exit code. With --dry-run the body
+// is only printed.
+func execBody(cmd *cobra.Command, snippet *core.Snippet, body string, vars map[string]string, opts runner.Options) error {
+	risks := runner.Risks(body)
+	if execDryRun {
+		printRisks(risks)
+		fmt.Println(body)
+		return nil
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	command, err := runner.Prepare(body, snippet.Language, cfg.Interpreters, opts)
+	if err != nil {
+		return err
+	}
+	defer command.Close()
+
+	if !execYes || opts.Confirm {
+		if err := confirmExec(snippet, body, command, risks); err != nil {
+			return err
+		}
+	} else {
+		// Warn even when not asking
+		printRisks(risks)
+	}
 
-	if err := execCmd.Run(); err != nil {
+	entry := &runlog.Entry{
+		SnippetID:   snippet.ID,
+		Title:       snippet.Title,
+		Language:    snippet.Language,
+		Interpreter: command.Interpreter,
+		Command:     body,
+		Vars:        vars,
+		Env:         command.Env,
+		Cwd:         command.Dir,
+		Start:       time.Now(),
+	}
+	if entry.Cwd == "" {
+		entry.Cwd, _ = os.Getwd()
+	}
+	if opts.Timeout > 0 {
+		entry.Timeout = opts.Timeout.String()
+	}
+
+	result, err := command.Run(context.Background())
+	if err != nil {
 		return fmt.Errorf("command execution failed: %w", err)
 	}
 
+	entry.DurationMS = result.Duration.Milliseconds()
+	entry.ExitCode = result.ExitCode
+	entry.TimedOut = result.TimedOut
+	if path := cfg.ExecLogPath(); path != "" {
+		if err := runlog.New(path).Append(entry); err != nil {
+			slog.Warn("failed to record run", "error", err)
+		}
+	}
+
+	if result.TimedOut {
+		fmt.Fprintf(os.Stderr, "snipgo: '%s' timed out after %s\n", snippet.Title, opts.Timeout)
+	}
+	if result.ExitCode != 0 {
+		// The snippet has reported its failure already
+		cmd.SilenceErrors = true
+		return exitCodeError(result.ExitCode)
+	}
+
 	return nil
 }
 
+// execOptions returns the run options set in a snippet's frontmatter,
+// overridden by the flags
+func execOptions(snippet *core.Snippet) (runner.Options, error) {
+	opts, err := runner.OptionsFromSnippet(snippet)
+	if err != nil {
+		return opts, fmt.Errorf("snippet '%s' has an %w", snippet.Title, err)
+	}
 
+	if execDir != "" {
+		opts.Dir = runner.ExpandPath(execDir)
+	}
+	for _, pair := range execEnv {
+		if name, _, ok := strings.Cut(pair, "="); !ok || name == "" {
+			return opts, fmt.Errorf("invalid --env %q, expected KEY=value", pair)
+		}
+		opts.Env = append(opts.Env, pair)
+	}
+	if execTimeout != "" {
+		timeout, err := runner.ParseTimeout(execTimeout)
+		if err != nil {
+			return opts, fmt.Errorf("invalid --timeout %q, expected a duration such as 30s", execTimeout)
+		}
+		opts.Timeout = timeout
+	}
+	if execInterpreter != "" {
+		opts.Interpreter = execInterpreter
+	}
+	return opts, nil
+}
+
+// confirmExec shows the command about to run, with warnings for its risky
+// parts, and asks whether to run it
+func confirmExec(snippet *core.Snippet, body string, command *runner.Command, risks []runner.Risk) error {
+	if !isatty.IsTerminal(os.Stdin.Fd()) && !isatty.IsCygwinTerminal(os.Stdin.Fd()) {
+		printRisks(risks)
+		if execYes {
+			return fmt.Errorf("snippet '%s' sets confirm: true, it can only be run from a terminal", snippet.Title)
+		}
+		return fmt.Errorf("running '%s' needs confirmation, pass --yes when stdin is not a terminal", snippet.Title)
+	}
+
+	fmt.Fprintf(os.Stderr, "%s (%s)\n", snippet.Title, command.Interpreter)
+	if command.Dir != "" {
+		fmt.Fprintf(os.Stderr, "in %s\n", command.Dir)
+	}
+	for _, pair := range command.Env {
+		fmt.Fprintf(os.Stderr, "with %s\n", pair)
+	}
+	fmt.Fprintln(os.Stderr)
+	for _, line := range strings.Split(body, "\n") {
+		fmt.Fprintf(os.Stderr, "  %s\n", line)
+	}
+	fmt.Fprintln(os.Stderr)
+	printRisks(risks)
+
+	ok, err := confirm("Run it?")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("cancelled")
+	}
+	return nil
+}
+
+// printRisks warns about the risky commands found in a body
+func printRisks(risks []runner.Risk) {
+	for _, risk := range risks {
+		fmt.Fprintf(os.Stderr, "WARNING: %s: %s\n", risk.Reason, risk.Match)
+	}
+}
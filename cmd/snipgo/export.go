@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"snipgo/internal/core"
+	"snipgo/internal/interop"
+
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export snippets for other tools",
+}
+
+var exportVSCodeCmd = &cobra.Command{
+	Use:   "vscode [query...]",
+	Short: "Export snippets as a VS Code snippet file",
+	Long: `Writes snippets to standard output as a VS Code .code-snippets file. Without a
+query all snippets are exported.
+
+Placeholders become tab stops, numbered in order of appearance, and the
+language becomes the scope. The prefix is the one imported from VS Code, or
+else derived from the title.`,
+	Example: `  snipgo export vscode --language go > ~/.config/Code/User/snippets/snipgo-go.code-snippets
+  snipgo export vscode tag:k8s > k8s.code-snippets`,
+	RunE: runExportVSCode,
+}
+
+var exportLanguage string
+
+func init() {
+	exportVSCodeCmd.Flags().StringVar(&exportLanguage, "language", "", "Only export snippets in this language")
+	exportVSCodeCmd.RegisterFlagCompletionFunc("language", completeLanguages)
+	exportCmd.AddCommand(exportVSCodeCmd)
+}
+
+func runExportVSCode(cmd *cobra.Command, args []string) error {
+	results, err := searchAll(strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+
+	var snippets []*core.Snippet
+	for _, r := range results {
+		if exportLanguage != "" && interop.VSCodeLanguage(r.Snippet.Language) != interop.VSCodeLanguage(exportLanguage) {
+			continue
+		}
+		snippets = append(snippets, r.Snippet)
+	}
+	if len(snippets) == 0 {
+		return fmt.Errorf("no snippets to export")
+	}
+
+	data, err := interop.ExportVSCode(snippets)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
@@ -2,11 +2,16 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
 
 	"snipgo/internal/core"
+	"snipgo/internal/tui"
+
+	"github.com/chzyer/readline"
+	"github.com/mattn/go-isatty"
 )
 
 // serializeSnippetForEdit creates a markdown file with frontmatter for editing
@@ -26,92 +31,316 @@ func parseSnippetFromEdit(content []byte) (*core.Snippet, error) {
 	return parsedSnippet, nil
 }
 
-// formatSnippetForFzf formats a snippet for fzf display in pet CLI style: [Title] Body #tag1 #tag2
-func formatSnippetForFzf(snippet *core.Snippet) string {
-	// Get first line of body for display
-	bodyFirstLine := ""
-	if snippet.Body != "" {
+// editInEditor opens content in $EDITOR and returns the edited content.
+// It returns an error if the file was left unmodified.
+func editInEditor(content []byte) ([]byte, error) {
+	// Get editor from environment variable, default to vi
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// Create temporary file
+	tmpFile, err := os.CreateTemp("", "snipgo-edit-*.md")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath) // Clean up temp file
+
+	// Write to temp file
+	if _, err := tmpFile.Write(content); err != nil {
+		tmpFile.Close()
+		return nil, fmt.Errorf("failed to write to temporary file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close temporary file: %w", err)
+	}
+
+	// Get file modification time before editing
+	beforeStat, err := os.Stat(tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat temporary file: %w", err)
+	}
+	beforeModTime := beforeStat.ModTime()
+
+	// Open editor
+	editCmd := exec.Command(editor, tmpPath)
+	editCmd.Stdin = os.Stdin
+	editCmd.Stdout = os.Stdout
+	editCmd.Stderr = os.Stderr
+
+	if err := editCmd.Run(); err != nil {
+		return nil, fmt.Errorf("editor exited with error: %w", err)
+	}
+
+	// Check if file was modified
+	afterStat, err := os.Stat(tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat temporary file after editing: %w", err)
+	}
+	afterModTime := afterStat.ModTime()
+
+	// If file wasn't modified, user might have cancelled
+	if beforeModTime.Equal(afterModTime) {
+		return nil, fmt.Errorf("file was not modified, edit cancelled")
+	}
+
+	// Read edited content
+	editedContent, err := os.ReadFile(tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read edited file: %w", err)
+	}
+
+	return editedContent, nil
+}
+
+// parseVarFlags parses --var name=value flags into a map
+func parseVarFlags(vars []string) (map[string]string, error) {
+	values := make(map[string]string, len(vars))
+	for _, v := range vars {
+		name, value, ok := strings.Cut(v, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --var %q, expected name=value", v)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// fillParams prompts for the placeholders in the body of snippet that have
+// no value in preset, pre-filling each prompt with the placeholder's default,
+// and returns the rendered body together with the values used. The body of
+// a snippet with params: false is returned as is.
+func fillParams(snippet *core.Snippet, preset map[string]string) (string, map[string]string, error) {
+	body := snippet.Body
+	if !snippet.ParamsEnabled() {
+		return body, nil, nil
+	}
+
+	params := core.ExtractParams(body)
+	values := make(map[string]string, len(params))
+	for _, p := range params {
+		if v, ok := preset[p.Name]; ok {
+			values[p.Name] = v
+		}
+	}
+
+	if len(values) < len(params) {
+		cfg, release := promptConfig()
+		defer release()
+		rl, err := readline.NewEx(cfg)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to start prompt: %w", err)
+		}
+		defer rl.Close()
+
+		for _, p := range params {
+			if _, ok := values[p.Name]; ok {
+				continue
+			}
+			rl.SetPrompt(p.Name + "> ")
+			value, err := rl.ReadlineWithDefault(p.Default)
+			if err != nil {
+				if err == io.EOF || err == readline.ErrInterrupt {
+					return "", nil, fmt.Errorf("cancelled")
+				}
+				return "", nil, fmt.Errorf("failed to read %s: %w", p.Name, err)
+			}
+			values[p.Name] = value
+		}
+	}
+
+	rendered, err := core.RenderParams(body, values)
+	if err != nil {
+		return "", nil, err
+	}
+	return rendered, values, nil
+}
+
+// promptConfig returns the readline configuration for prompts. When stdout
+// is captured, as by the widgets of shell-init, the prompts are drawn on the
+// terminal instead. The returned function releases it.
+func promptConfig() (*readline.Config, func()) {
+	cfg := &readline.Config{}
+	if isatty.IsTerminal(os.Stdout.Fd()) {
+		return cfg, func() {}
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return cfg, func() {}
+	}
+	cfg.Stdout = tty
+	cfg.Stderr = tty
+	cfg.FuncIsTerminal = func() bool {
+		return isatty.IsTerminal(os.Stdin.Fd())
+	}
+	return cfg, func() { tty.Close() }
+}
+
+// confirm asks a yes/no question, defaulting to no
+func confirm(question string) (bool, error) {
+	cfg, release := promptConfig()
+	defer release()
+	cfg.Prompt = question + " [y/N] "
+	rl, err := readline.NewEx(cfg)
+	if err != nil {
+		return false, fmt.Errorf("failed to start prompt: %w", err)
+	}
+	defer rl.Close()
+
+	answer, err := rl.Readline()
+	if err != nil {
+		if err == io.EOF || err == readline.ErrInterrupt {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read answer: %w", err)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// shortID returns the first 8 characters of a snippet ID for display
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+// ANSI escape sequences used to highlight matches
+const (
+	ansiHighlight = "\x1b[1;33m"
+	ansiReset     = "\x1b[0m"
+)
+
+// highlightANSI highlights the character ranges of text with ANSI colors
+func highlightANSI(text string, ranges []core.MatchRange) string {
+	if len(ranges) == 0 {
+		return text
+	}
+
+	runes := []rune(text)
+	var b strings.Builder
+	last := 0
+	for _, r := range ranges {
+		start, end := min(r.Start, len(runes)), min(r.End, len(runes))
+		if start < last {
+			continue
+		}
+		b.WriteString(string(runes[last:start]))
+		b.WriteString(ansiHighlight)
+		b.WriteString(string(runes[start:end]))
+		b.WriteString(ansiReset)
+		last = end
+	}
+	b.WriteString(string(runes[last:]))
+	return b.String()
+}
+
+// formatSnippetForFzf formats a search result for fzf display in pet CLI style: [Title] Body #tag1 #tag2
+// Matches are highlighted, and the body shows the excerpt around the first body match if any.
+func formatSnippetForFzf(result *core.SearchResult) string {
+	snippet := result.Snippet
+
+	var titleRanges []core.MatchRange
+	tagRanges := make(map[int][]core.MatchRange)
+	for _, match := range result.Matches {
+		switch match.Field {
+		case "title":
+			titleRanges = match.Ranges
+		case "tags":
+			tagRanges[match.Index] = match.Ranges
+		}
+	}
+
+	// Get the excerpt, or the first line of body for display
+	body := ""
+	if result.Excerpt != nil {
+		body = highlightANSI(result.Excerpt.Text, result.Excerpt.Ranges)
+	} else if snippet.Body != "" {
 		bodyLines := strings.Split(snippet.Body, "\n")
-		bodyFirstLine = bodyLines[0]
-		if len(bodyFirstLine) > 100 {
-			bodyFirstLine = bodyFirstLine[:100] + "..."
+		body = bodyLines[0]
+		if len(body) > 100 {
+			body = body[:100] + "..."
 		}
 	}
 
 	// Format: [Title] Body
-	line := fmt.Sprintf("[%s] %s", snippet.Title, bodyFirstLine)
+	line := fmt.Sprintf("[%s] %s", highlightANSI(snippet.Title, titleRanges), body)
 
 	// Add tags if present
-	if len(snippet.Tags) > 0 {
-		tagStr := strings.Join(snippet.Tags, " #")
-		line += " #" + tagStr
+	for i, tag := range snippet.Tags {
+		line += " #" + highlightANSI(tag, tagRanges[i])
 	}
 
 	return line
 }
 
-// selectSnippetWithFzf uses fzf to interactively select a snippet from the given list
-func selectSnippetWithFzf(snippets []*core.Snippet) (*core.Snippet, error) {
-	if len(snippets) == 0 {
+// selectWithFzf uses fzf to interactively select snippets from search results, highlighting the matches.
+// With multi, several snippets can be marked with Tab.
+func selectWithFzf(results []*core.SearchResult, multi bool) ([]*core.Snippet, error) {
+	if len(results) == 0 {
 		return nil, fmt.Errorf("no snippets to select from")
 	}
 
 	// Check if fzf is available
 	if _, err := exec.LookPath("fzf"); err != nil {
-		return nil, fmt.Errorf("fzf is not installed. Please install fzf first: https://github.com/junegunn/fzf")
+		return nil, fmt.Errorf("fzf is not installed. Please install fzf first: https://github.com/junegunn/fzf, or set 'picker: builtin'")
 	}
 
-	// Build fzf input: format each snippet
+	// Build fzf input: each line is the snippet ID, a tab and the formatted snippet.
+	// Only the formatted part is shown and searched.
 	var fzfInput strings.Builder
-	snippetMap := make(map[string]*core.Snippet) // Map from formatted line to snippet
-	for _, snippet := range snippets {
-		formatted := formatSnippetForFzf(snippet)
-		fzfInput.WriteString(formatted)
+	snippetMap := make(map[string]*core.Snippet, len(results))
+	for _, result := range results {
+		fzfInput.WriteString(result.Snippet.ID)
+		fzfInput.WriteString("\t")
+		fzfInput.WriteString(strings.ReplaceAll(formatSnippetForFzf(result), "\t", " "))
 		fzfInput.WriteString("\n")
-		snippetMap[formatted] = snippet
+		snippetMap[result.Snippet.ID] = result.Snippet
 	}
 
 	// Run fzf
-	cmd := exec.Command("fzf", "--ansi", "--height", "40%")
+	fzfArgs := []string{"--ansi", "--height", "40%", "--delimiter", "\t", "--with-nth", "2.."}
+	if multi {
+		fzfArgs = append(fzfArgs, "--multi")
+	}
+	cmd := exec.Command("fzf", fzfArgs...)
 	cmd.Stdin = strings.NewReader(fzfInput.String())
 	cmd.Stderr = os.Stderr
 
 	output, err := cmd.Output()
 	if err != nil {
-		// fzf returns exit code 1 when user cancels (ESC)
-		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
-			return nil, fmt.Errorf("selection cancelled")
+		// fzf returns exit code 1 when nothing matched and 130 when the user cancels (ESC)
+		if exitErr, ok := err.(*exec.ExitError); ok && (exitErr.ExitCode() == 1 || exitErr.ExitCode() == 130) {
+			return nil, tui.ErrCancelled
 		}
 		return nil, fmt.Errorf("fzf error: %w", err)
 	}
 
-	// Parse selected line
-	selectedLine := strings.TrimSpace(string(output))
-	if selectedLine == "" {
-		return nil, fmt.Errorf("no snippet selected")
-	}
-
-	// Find snippet by matching the selected line
-	selectedSnippet, found := snippetMap[selectedLine]
-	if !found {
-		// If exact match not found, try to extract title from the line
-		// Format: [Title] Body #tags
-		if strings.HasPrefix(selectedLine, "[") {
-			endIdx := strings.Index(selectedLine, "]")
-			if endIdx > 0 {
-				title := selectedLine[1:endIdx]
-				// Find snippet by title
-				for _, snippet := range snippets {
-					if snippet.Title == title {
-						return snippet, nil
-					}
-				}
-			}
+	// Map the selected lines back to snippets by ID
+	var selected []*core.Snippet
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		id, _, _ := strings.Cut(line, "\t")
+		if id == "" {
+			continue
 		}
-		return nil, fmt.Errorf("could not find selected snippet")
+		snippet, found := snippetMap[id]
+		if !found {
+			return nil, fmt.Errorf("could not find selected snippet")
+		}
+		selected = append(selected, snippet)
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no snippet selected")
 	}
 
-	return selectedSnippet, nil
+	return selected, nil
 }
-
-
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"snipgo/internal/core"
+
+	"github.com/spf13/cobra"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history [id | title | query...]",
+	Short: "List previous versions of a snippet",
+	Long: `Lists the versions of a snippet kept each time it was saved, most recent first.
+
+The snippet is named by its ID, an ID prefix, its exact title or a search
+query, and selected interactively if there is no argument or several
+snippets match. Versions are numbered from 1 for the most recent one; diff and revert accept
+either the number or the rev name. The number of versions kept is set with
+history_limit in the config file.`,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeSnippets,
+	RunE:              runHistory,
+}
+
+var diffCmd = &cobra.Command{
+	Use:               "diff <id | title> [rev]",
+	Short:             "Show changes since a previous version of a snippet",
+	Long:              "Shows a unified diff from a previous version of a snippet (by default the most recent one) to its current version",
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeSnippets,
+	RunE:              runDiff,
+}
+
+var revertCmd = &cobra.Command{
+	Use:               "revert <id | title> <rev>",
+	Short:             "Restore a previous version of a snippet",
+	Long:              "Saves a previous version of a snippet as its current version. The replaced version is kept in the history, so a revert can be undone.",
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeSnippets,
+	RunE:              runRevert,
+}
+
+// diffContext is the number of unchanged lines shown around changes
+const diffContext = 3
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	snippet, err := resolveSnippet(args)
+	if err != nil {
+		return err
+	}
+
+	versions, err := manager.History(snippet.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(versions) == 0 {
+		fmt.Printf("No previous versions of '%s'.\n", snippet.Title)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "#\tReplaced\tTitle\tRev")
+	fmt.Fprintln(w, "-\t--------\t-----\t---")
+
+	for _, v := range versions {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n",
+			v.Number, v.SavedAt.Local().Format("2006-01-02 15:04:05"), v.Snippet.Title, v.Rev)
+	}
+
+	return w.Flush()
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	snippet, err := resolveSnippet(args[:1])
+	if err != nil {
+		return err
+	}
+
+	rev := "1"
+	if len(args) > 1 {
+		rev = args[1]
+	}
+	version, err := manager.GetVersion(snippet.ID, rev)
+	if err != nil {
+		return err
+	}
+
+	before, err := core.SerializeFrontmatter(version.Snippet)
+	if err != nil {
+		return fmt.Errorf("failed to serialize snippet: %w", err)
+	}
+	after, err := core.SerializeFrontmatter(snippet)
+	if err != nil {
+		return fmt.Errorf("failed to serialize snippet: %w", err)
+	}
+
+	writeUnifiedDiff(os.Stdout, version.Rev, "current", core.DiffLines(string(before), string(after)))
+	return nil
+}
+
+func runRevert(cmd *cobra.Command, args []string) error {
+	snippet, err := resolveSnippet(args[:1])
+	if err != nil {
+		return err
+	}
+
+	version, err := manager.GetVersion(snippet.ID, args[1])
+	if err != nil {
+		return err
+	}
+	reverted, err := manager.Revert(snippet.ID, version.Rev)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Reverted snippet '%s' to version %d (%s)\n", reverted.Title, version.Number, version.Rev)
+	return nil
+}
+
+// writeUnifiedDiff prints a line diff in unified format, with diffContext
+// unchanged lines around each change
+func writeUnifiedDiff(w io.Writer, from, to string, lines []core.DiffLine) {
+	fmt.Fprintf(w, "--- %s\n+++ %s\n", from, to)
+
+	// Mark the lines to print: changes and their context
+	show := make([]bool, len(lines))
+	for i, line := range lines {
+		if line.Op == core.DiffEqual {
+			continue
+		}
+		for j := max(0, i-diffContext); j <= min(len(lines)-1, i+diffContext); j++ {
+			show[j] = true
+		}
+	}
+
+	aLine, bLine := 1, 1
+	for i := 0; i < len(lines); {
+		if !show[i] {
+			if lines[i].Op != core.DiffInsert {
+				aLine++
+			}
+			if lines[i].Op != core.DiffDelete {
+				bLine++
+			}
+			i++
+			continue
+		}
+
+		// A hunk is a run of lines to print
+		end := i
+		aCount, bCount := 0, 0
+		for ; end < len(lines) && show[end]; end++ {
+			if lines[end].Op != core.DiffInsert {
+				aCount++
+			}
+			if lines[end].Op != core.DiffDelete {
+				bCount++
+			}
+		}
+		fmt.Fprintf(w, "@@ -%d,%d +%d,%d @@\n", aLine, aCount, bLine, bCount)
+		for ; i < end; i++ {
+			prefix := " "
+			switch lines[i].Op {
+			case core.DiffDelete:
+				prefix = "-"
+			case core.DiffInsert:
+				prefix = "+"
+			}
+			fmt.Fprintln(w, prefix+lines[i].Text)
+		}
+		aLine += aCount
+		bLine += bCount
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"snipgo/internal/core"
+	"snipgo/internal/interop"
+
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import snippets from other snippet managers",
+}
+
+var importPetCmd = &cobra.Command{
+	Use:   "pet [path]",
+	Short: "Import snippets from pet",
+	Long: `Imports the snippets of a pet snippet file (by default the one pet uses,
+~/.config/pet/snippet.toml unless set otherwise in pet's config.toml).
+
+Descriptions become titles, commands become bodies and tags carry over. Pet
+placeholders such as <name=default> are translated to {{name:default}}.
+Snippets whose command matches an existing snippet are skipped.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runImportPet,
+}
+
+var importVSCodeCmd = &cobra.Command{
+	Use:   "vscode <file>",
+	Short: "Import snippets from a VS Code snippet file",
+	Long: `Imports the snippets of a VS Code snippet file, either a global .code-snippets
+file or a language specific one such as go.json.
+
+Snippet names become titles and the scope, or the language of the file,
+becomes the language. Tab stops are translated to placeholders: ${1:name}
+becomes {{name}}, ${1:other text} becomes {{p1:other text}}. The prefix and
+description are kept in the frontmatter, so that export vscode gives them
+back. Snippets whose body matches an existing snippet are skipped.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImportVSCode,
+}
+
+var importDryRun bool
+
+func init() {
+	importCmd.PersistentFlags().BoolVarP(&importDryRun, "dry-run", "n", false, "Show what would be imported without saving")
+	importCmd.AddCommand(importPetCmd)
+	importCmd.AddCommand(importVSCodeCmd)
+}
+
+func runImportPet(cmd *cobra.Command, args []string) error {
+	var path string
+	if len(args) > 0 {
+		path = args[0]
+	} else {
+		var err error
+		if path, err = interop.DefaultPetSnippetFile(); err != nil {
+			return err
+		}
+	}
+
+	pets, err := interop.ReadPetFile(path)
+	if err != nil {
+		return err
+	}
+	snippets := make([]*core.Snippet, 0, len(pets))
+	for _, p := range pets {
+		snippets = append(snippets, p.Snippet())
+	}
+
+	report, err := interop.Import(manager, snippets, importDryRun)
+	printImportReport(report)
+	return err
+}
+
+func runImportVSCode(cmd *cobra.Command, args []string) error {
+	path := args[0]
+	entries, err := interop.ReadVSCodeFile(path)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	language := interop.VSCodeFileLanguage(path)
+	snippets := make([]*core.Snippet, 0, len(entries))
+	for _, name := range names {
+		snippets = append(snippets, entries[name].Snippet(name, language))
+	}
+
+	report, err := interop.Import(manager, snippets, importDryRun)
+	printImportReport(report)
+	return err
+}
+
+// printImportReport lists the imported and skipped snippets
+func printImportReport(report *interop.ImportReport) {
+	if report == nil {
+		return
+	}
+
+	verb := "Imported"
+	if importDryRun {
+		verb = "Would import"
+	}
+	for _, s := range report.Imported {
+		fmt.Printf("%s '%s'\n", verb, s.Title)
+	}
+	for _, s := range report.Skipped {
+		fmt.Printf("Skipped '%s': %s\n", s.Snippet.Title, s.Reason)
+	}
+	fmt.Printf("%s %d snippets, skipped %d\n", verb, len(report.Imported), len(report.Skipped))
+}
@@ -1,10 +1,7 @@
 package main
 
 import (
-	"fmt"
 	"os"
-	"strings"
-	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 )
@@ -12,41 +9,40 @@ import (
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all snippets",
-	Long:  "Lists all snippets in a table format",
-	RunE:  runList,
+	Long: `Lists all snippets in a table format, sorted by title.
+
+Use --output json, yaml, csv or tsv for machine-readable output with full IDs
+and bodies, or --format to print each snippet with a Go template, e.g.
+--format '{{.ID}} {{.Title}} {{join .Tags ","}}'. Exits with status 1 if
+there are no snippets.`,
+	Args: cobra.NoArgs,
+	RunE: runList,
+}
+
+func init() {
+	addOutputFlags(listCmd)
 }
 
 func runList(cmd *cobra.Command, args []string) error {
-	snippets := manager.GetAll()
+	snippets := sortedSnippets()
 
-	if len(snippets) == 0 {
-		fmt.Println("No snippets found.")
-		return nil
+	records := make([]*snippetRecord, len(snippets))
+	for i, snippet := range snippets {
+		records[i] = newSnippetRecord(snippet)
 	}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-	fmt.Fprintln(w, "ID\tTitle\tTags\tLanguage\tFavorite")
-	fmt.Fprintln(w, "---\t-----\t----\t--------\t--------")
-
-	for _, snippet := range snippets {
-		idShort := snippet.ID[:8]
-		tags := strings.Join(snippet.Tags, ", ")
-		if tags == "" {
-			tags = "-"
-		}
-		language := snippet.Language
-		if language == "" {
-			language = "-"
+	if machineOutput() {
+		if err := writeRecords(os.Stdout, records, false); err != nil {
+			return err
 		}
-		favorite := "No"
-		if snippet.IsFavorite {
-			favorite = "Yes"
+	} else if len(records) > 0 {
+		if err := writeTable(os.Stdout, records, false); err != nil {
+			return err
 		}
-
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
-			idShort, snippet.Title, tags, language, favorite)
 	}
 
-	return w.Flush()
+	if len(records) == 0 {
+		return errNotFound
+	}
+	return nil
 }
-
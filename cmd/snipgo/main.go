@@ -1,11 +1,15 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
 	"strings"
 
 	"snipgo/internal/core"
+	"snipgo/internal/gitsync"
+	"snipgo/internal/tui"
 
 	"github.com/spf13/cobra"
 )
@@ -20,11 +24,31 @@ var (
 
 var logLevel string
 
+// Exit codes, stable for scripts
+const (
+	exitNotFound = 1 // no snippet matched, or none was selected
+	exitError    = 2
+)
+
+// exitCodeError makes snipgo exit with a given code, such as that of a
+// snippet run by exec
+type exitCodeError int
+
+func (e exitCodeError) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
+// errNotFound is wrapped by the errors of lookups that match no snippet
+var errNotFound = errors.New("no snippets found")
+
 var rootCmd = &cobra.Command{
 	Use:     "snipgo",
 	Short:   "SnipGo - Local-First Snippet Manager",
 	Long:    "SnipGo is a local-first snippet manager that stores snippets as Markdown files.",
 	Version: version,
+	// Errors such as "no snippets found" are expected in scripts and don't
+	// call for the usage text
+	SilenceUsage: true,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		level, _ := cmd.Flags().GetString("log-level")
 		setupLogger(level)
@@ -34,12 +58,17 @@ var rootCmd = &cobra.Command{
 		manager, err = core.NewManager()
 		if err != nil {
 			slog.Error("failed to initialize manager", "error", err)
-			os.Exit(1)
+			os.Exit(exitError)
 		}
 
 		if err := manager.LoadAll(); err != nil {
 			slog.Error("failed to load snippets", "error", err)
-			os.Exit(1)
+			os.Exit(exitError)
+		}
+
+		// Commit changes when the data directory is a git repository
+		if repo, err := gitsync.Open(manager.Storage().GetSnippetsDir()); err == nil {
+			manager.SetCommitter(repo)
 		}
 	},
 }
@@ -48,13 +77,29 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&logLevel, "log-level", "l", "info", "Set log level (debug, info, warn, error)")
 
 	rootCmd.AddCommand(newCmd)
+	rootCmd.AddCommand(prevCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(searchCmd)
+	rootCmd.AddCommand(showCmd)
 	rootCmd.AddCommand(copyCmd)
 	rootCmd.AddCommand(execCmd)
+	rootCmd.AddCommand(runsCmd)
+	rootCmd.AddCommand(rerunCmd)
 	rootCmd.AddCommand(editCmd)
+	rootCmd.AddCommand(rmCmd)
+	rootCmd.AddCommand(trashCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(revertCmd)
+	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(exportCmd)
 	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(shellInitCmd)
+	completionCmd.AddCommand(completionBashCmd)
 	completionCmd.AddCommand(completionZshCmd)
+	completionCmd.AddCommand(completionFishCmd)
+	completionCmd.AddCommand(completionPowerShellCmd)
 	rootCmd.AddCommand(completionCmd)
 }
 
@@ -91,6 +136,13 @@ func main() {
 	setupLogger("info")
 
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		var code exitCodeError
+		if errors.As(err, &code) {
+			os.Exit(int(code))
+		}
+		if errors.Is(err, errNotFound) || errors.Is(err, core.ErrNotFound) || errors.Is(err, tui.ErrCancelled) {
+			os.Exit(exitNotFound)
+		}
+		os.Exit(exitError)
 	}
 }
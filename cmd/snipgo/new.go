@@ -3,34 +3,140 @@ package main
 import (
 	"fmt"
 	"io"
+	"os"
 	"strings"
 
 	"snipgo/internal/core"
 
+	"github.com/atotto/clipboard"
 	"github.com/chzyer/readline"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
 )
 
 var newCmd = &cobra.Command{
 	Use:   "new",
 	Short: "Create a new snippet",
-	Long:  "Interactively create a new snippet by entering description and command",
-	Args:  cobra.NoArgs,
-	RunE:  runNew,
+	Long: `Creates a new snippet.
+
+Without flags, you are prompted for a description and a one-line command.
+The metadata can be given with flags instead, and the body read from:
+
+  stdin           when it is not a terminal: kubectl get pods -o yaml | snipgo new -t "Pods"
+  --clipboard     the current clipboard content
+  --from-history  a command picked from your shell history, see "snipgo prev"
+  --editor        $EDITOR, opened on a Markdown file with the frontmatter filled in
+
+Metadata flags given in a terminal without another body source open $EDITOR
+as well, so that a multi-line body can be written.`,
+	Args: cobra.NoArgs,
+	RunE: runNew,
+}
+
+var (
+	newTitle     string
+	newTags      []string
+	newLanguage  string
+	newFavorite  bool
+	newClipboard bool
+	newEditor    bool
+)
+
+func init() {
+	newCmd.Flags().StringVarP(&newTitle, "title", "t", "", "Title of the snippet")
+	newCmd.Flags().StringSliceVar(&newTags, "tags", nil, "Comma-separated tags, e.g. go,api")
+	newCmd.Flags().StringVar(&newLanguage, "language", "", "Language of the body, e.g. sh or go")
+	newCmd.Flags().BoolVar(&newFavorite, "favorite", false, "Mark the snippet as a favorite")
+	newCmd.Flags().BoolVarP(&newClipboard, "clipboard", "c", false, "Read the body from the clipboard")
+	newCmd.Flags().BoolVarP(&newEditor, "editor", "e", false, "Write the snippet in $EDITOR")
+	newCmd.Flags().BoolVar(&newFromHistory, "from-history", false, "Pick the body from recent shell history commands")
+	addHistoryFlags(newCmd)
+	newCmd.RegisterFlagCompletionFunc("tags", completeTags)
+	newCmd.RegisterFlagCompletionFunc("language", completeLanguages)
+	newCmd.MarkFlagsMutuallyExclusive("clipboard", "from-history")
 }
 
 func runNew(cmd *cobra.Command, args []string) error {
-	// Prompt for description (title)
-	description, err := readline.Line("Description> ")
-	if err != nil {
-		if err == io.EOF {
-			return fmt.Errorf("cancelled")
+	piped := !newFromHistory && !isatty.IsTerminal(os.Stdin.Fd()) && !isatty.IsCygwinTerminal(os.Stdin.Fd())
+	if newEditor && piped {
+		return fmt.Errorf("--editor needs a terminal, it can't be used with a body on stdin")
+	}
+
+	snippet := core.NewSnippet(strings.TrimSpace(newTitle))
+	for _, tag := range newTags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			snippet.Tags = append(snippet.Tags, tag)
 		}
-		return fmt.Errorf("failed to read description: %w", err)
 	}
-	description = strings.TrimSpace(description)
-	if description == "" {
-		return fmt.Errorf("description cannot be empty")
+	snippet.Language = strings.TrimSpace(newLanguage)
+	snippet.IsFavorite = newFavorite
+
+	switch {
+	case newFromHistory:
+		body, err := pickFromHistory()
+		if err != nil {
+			return err
+		}
+		snippet.Body = body
+		if snippet.Language == "" {
+			snippet.Language = "sh"
+		}
+	case newClipboard:
+		body, err := clipboard.ReadAll()
+		if err != nil {
+			return fmt.Errorf("failed to read clipboard: %w", err)
+		}
+		snippet.Body = trimBody(body)
+	case piped:
+		body, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read stdin: %w", err)
+		}
+		snippet.Body = trimBody(string(body))
+	case !newEditor && snippet.Title == "" && len(snippet.Tags) == 0 && snippet.Language == "" && !snippet.IsFavorite:
+		return promptNew()
+	}
+
+	// Without a body from the history, the clipboard or stdin, it is
+	// written in the editor
+	if newEditor || !newFromHistory && !newClipboard && !piped {
+		edited, err := editNewSnippet(snippet)
+		if err != nil {
+			return err
+		}
+		snippet = edited
+	} else {
+		if snippet.Title == "" && newFromHistory {
+			fmt.Println(snippet.Body)
+			title, err := promptTitle()
+			if err != nil {
+				return err
+			}
+			snippet.Title = title
+		}
+		if snippet.Title == "" {
+			return fmt.Errorf("title cannot be empty, set it with --title")
+		}
+		if snippet.Body == "" {
+			return fmt.Errorf("body cannot be empty")
+		}
+	}
+
+	if err := manager.Save(snippet); err != nil {
+		return fmt.Errorf("failed to save snippet: %w", err)
+	}
+
+	fmt.Printf("Snippet saved: %s\n", snippet.Title)
+	return nil
+}
+
+// promptNew asks for a description and a command and saves them as a new
+// snippet
+func promptNew() error {
+	// Prompt for description (title)
+	description, err := promptTitle()
+	if err != nil {
+		return err
 	}
 
 	// Prompt for command (body)
@@ -59,4 +165,55 @@ func runNew(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// promptTitle asks for the description of a new snippet, its title
+func promptTitle() (string, error) {
+	description, err := readline.Line("Description> ")
+	if err != nil {
+		if err == io.EOF {
+			return "", fmt.Errorf("cancelled")
+		}
+		return "", fmt.Errorf("failed to read description: %w", err)
+	}
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return "", fmt.Errorf("description cannot be empty")
+	}
+	return description, nil
+}
+
+// editNewSnippet opens a new snippet in $EDITOR, its frontmatter filled in
+// from the flags, and returns the snippet written there
+func editNewSnippet(snippet *core.Snippet) (*core.Snippet, error) {
+	content, err := serializeSnippetForEdit(snippet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize snippet: %w", err)
+	}
 
+	editedContent, err := editInEditor(content)
+	if err != nil {
+		return nil, err
+	}
+
+	edited, err := parseSnippetFromEdit(editedContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse edited content: %w", err)
+	}
+	// Keep the generated ID and creation time
+	edited.ID = snippet.ID
+	edited.CreatedAt = snippet.CreatedAt
+
+	if err := edited.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid snippet: %w", err)
+	}
+	if strings.TrimSpace(edited.Body) == "" {
+		return nil, fmt.Errorf("body cannot be empty")
+	}
+	return edited, nil
+}
+
+// trimBody removes the trailing newlines of a body read from a pipe or the
+// clipboard, along with surrounding blank lines
+func trimBody(body string) string {
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	return strings.Trim(body, "\n")
+}
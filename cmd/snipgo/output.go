@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"snipgo/internal/core"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Machine-readable output formats for --output
+const (
+	outputJSON = "json"
+	outputYAML = "yaml"
+	outputCSV  = "csv"
+	outputTSV  = "tsv"
+)
+
+var (
+	outputFormat   string
+	outputTemplate string
+)
+
+// addOutputFlags adds the --output and --format flags to a command
+func addOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format: json, yaml, csv or tsv")
+	cmd.Flags().StringVar(&outputTemplate, "format", "", "Format each snippet with a Go template, e.g. '{{.Title}} {{.Tags}}'")
+	cmd.MarkFlagsMutuallyExclusive("output", "format")
+}
+
+// machineOutput reports whether --output or --format was given
+func machineOutput() bool {
+	return outputFormat != "" || outputTemplate != ""
+}
+
+// snippetRecord is a snippet as written by --output and seen by --format
+// templates
+type snippetRecord struct {
+	ID         string    `json:"id" yaml:"id"`
+	Title      string    `json:"title" yaml:"title"`
+	Tags       []string  `json:"tags" yaml:"tags"`
+	Language   string    `json:"language" yaml:"language"`
+	IsFavorite bool      `json:"is_favorite" yaml:"is_favorite"`
+	CreatedAt  time.Time `json:"created_at" yaml:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" yaml:"updated_at"`
+	Body       string    `json:"body" yaml:"body"`
+	Score      float64   `json:"score,omitempty" yaml:"score,omitempty"` // search relevance, higher is better
+}
+
+func newSnippetRecord(snippet *core.Snippet) *snippetRecord {
+	tags := snippet.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return &snippetRecord{
+		ID:         snippet.ID,
+		Title:      snippet.Title,
+		Tags:       tags,
+		Language:   snippet.Language,
+		IsFavorite: snippet.IsFavorite,
+		CreatedAt:  snippet.CreatedAt,
+		UpdatedAt:  snippet.UpdatedAt,
+		Body:       snippet.Body,
+	}
+}
+
+// snippetRecords converts search results to records, keeping their order
+func snippetRecords(results []*core.SearchResult) []*snippetRecord {
+	records := make([]*snippetRecord, len(results))
+	for i, result := range results {
+		records[i] = newSnippetRecord(result.Snippet)
+		records[i].Score = result.Score
+	}
+	return records
+}
+
+// writeRecords writes records in the format chosen with --output or
+// --format. If single is set, JSON and YAML write the one record as an
+// object rather than a list.
+func writeRecords(w io.Writer, records []*snippetRecord, single bool) error {
+	if outputTemplate != "" {
+		return writeTemplate(w, records)
+	}
+
+	var doc any = records
+	if single && len(records) == 1 {
+		doc = records[0]
+	}
+
+	switch strings.ToLower(outputFormat) {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(doc)
+	case outputYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return fmt.Errorf("failed to encode YAML: %w", err)
+		}
+		return enc.Close()
+	case outputCSV:
+		return writeDelimited(w, records, ',')
+	case outputTSV:
+		return writeDelimited(w, records, '\t')
+	default:
+		return fmt.Errorf("invalid --output %q, expected json, yaml, csv or tsv", outputFormat)
+	}
+}
+
+// recordColumns are the header of CSV and TSV output
+var recordColumns = []string{"id", "title", "tags", "language", "is_favorite", "created_at", "updated_at", "score", "body"}
+
+// writeDelimited writes records as CSV, or as TSV if sep is a tab. TSV has
+// no quoting, so tabs, newlines and backslashes in values are escaped as
+// \t, \n and \\ instead.
+func writeDelimited(w io.Writer, records []*snippetRecord, sep rune) error {
+	rows := [][]string{recordColumns}
+	for _, r := range records {
+		rows = append(rows, []string{
+			r.ID,
+			r.Title,
+			strings.Join(r.Tags, ","),
+			r.Language,
+			strconv.FormatBool(r.IsFavorite),
+			r.CreatedAt.Format(time.RFC3339),
+			r.UpdatedAt.Format(time.RFC3339),
+			strconv.FormatFloat(r.Score, 'f', -1, 64),
+			r.Body,
+		})
+	}
+
+	if sep == '\t' {
+		escaper := strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+		for _, row := range rows {
+			for i := range row {
+				row[i] = escaper.Replace(row[i])
+			}
+			if _, err := fmt.Fprintln(w, strings.Join(row, "\t")); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = sep
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// templateFuncs are the functions available to --format templates
+var templateFuncs = template.FuncMap{
+	"join": strings.Join,
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"short": shortID,
+}
+
+// writeTemplate executes the --format template for each record, each on
+// its own line
+func writeTemplate(w io.Writer, records []*snippetRecord) error {
+	tmpl, err := template.New("format").Funcs(templateFuncs).Parse(outputTemplate)
+	if err != nil {
+		return fmt.Errorf("invalid --format template: %w", err)
+	}
+
+	for _, r := range records {
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, r); err != nil {
+			return fmt.Errorf("failed to execute --format template: %w", err)
+		}
+		out := sb.String()
+		if !strings.HasSuffix(out, "\n") {
+			out += "\n"
+		}
+		if _, err := io.WriteString(w, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTable writes records as the human-readable table of list and
+// search, with a score column if scored is set
+func writeTable(w io.Writer, records []*snippetRecord, scored bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	if scored {
+		fmt.Fprintln(tw, "ID\tTitle\tTags\tLanguage\tFavorite\tScore")
+		fmt.Fprintln(tw, "---\t-----\t----\t--------\t--------\t-----")
+	} else {
+		fmt.Fprintln(tw, "ID\tTitle\tTags\tLanguage\tFavorite")
+		fmt.Fprintln(tw, "---\t-----\t----\t--------\t--------")
+	}
+
+	for _, r := range records {
+		tags := strings.Join(r.Tags, ", ")
+		if tags == "" {
+			tags = "-"
+		}
+		language := r.Language
+		if language == "" {
+			language = "-"
+		}
+		favorite := "No"
+		if r.IsFavorite {
+			favorite = "Yes"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s", shortID(r.ID), r.Title, tags, language, favorite)
+		if scored {
+			fmt.Fprintf(tw, "\t%.2f", r.Score)
+		}
+		fmt.Fprintln(tw)
+	}
+
+	return tw.Flush()
+}
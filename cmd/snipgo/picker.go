@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"snipgo/internal/config"
+	"snipgo/internal/core"
+	"snipgo/internal/tui"
+)
+
+// pickSnippets lets the user select snippets interactively, starting from
+// query, with the picker set in the configuration
+func pickSnippets(query string, multi bool) ([]*core.Snippet, error) {
+	if len(manager.GetAll()) == 0 {
+		return nil, errNotFound
+	}
+	return pick(query, multi, searchAll)
+}
+
+// pickSnippet lets the user select a single snippet interactively
+func pickSnippet(query string) (*core.Snippet, error) {
+	selected, err := pickSnippets(query, false)
+	if err != nil {
+		return nil, err
+	}
+	return selected[0], nil
+}
+
+// pickFrom lets the user select among candidates, which are listed in
+// their order until the user types a query
+func pickFrom(candidates []*core.Snippet, multi bool) ([]*core.Snippet, error) {
+	ids := make(map[string]bool, len(candidates))
+	for _, snippet := range candidates {
+		ids[snippet.ID] = true
+	}
+
+	search := func(query string) ([]*core.SearchResult, error) {
+		if strings.TrimSpace(query) == "" {
+			results := make([]*core.SearchResult, len(candidates))
+			for i, snippet := range candidates {
+				results[i] = &core.SearchResult{Snippet: snippet}
+			}
+			return results, nil
+		}
+
+		all, err := manager.SearchQuery(query)
+		if err != nil {
+			return nil, err
+		}
+		var results []*core.SearchResult
+		for _, result := range all {
+			if ids[result.Snippet.ID] {
+				results = append(results, result)
+			}
+		}
+		return results, nil
+	}
+	return pick("", multi, search)
+}
+
+// pick runs the picker set in the configuration over the results of search
+func pick(query string, multi bool, search tui.SearchFunc) ([]*core.Snippet, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if cfg.Picker == config.PickerFzf {
+		results, err := search(query)
+		if err != nil {
+			return nil, err
+		}
+		if len(results) == 0 {
+			return nil, fmt.Errorf("%w for query: %s", errNotFound, query)
+		}
+		return selectWithFzf(results, multi)
+	}
+
+	return tui.Pick(tui.Options{
+		Query:  query,
+		Multi:  multi,
+		Search: search,
+	})
+}
+
+// searchAll returns the results of query, or every snippet if it is empty,
+// sorted by title
+func searchAll(query string) ([]*core.SearchResult, error) {
+	return manager.SearchQuery(query)
+}
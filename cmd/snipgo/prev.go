@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"snipgo/internal/core"
+	"snipgo/internal/shellhist"
+
+	"github.com/sahilm/fuzzy"
+	"github.com/spf13/cobra"
+)
+
+var prevCmd = &cobra.Command{
+	Use:   "prev",
+	Short: "Save a command from shell history as a snippet",
+	Long: `Lets you pick one of the last commands of your shell history and saves it
+as a new snippet, like "snipgo new --from-history".
+
+The history of bash, zsh (plain or extended format) and fish is read from
+the shell's history file; the shell is taken from $SHELL unless --shell is
+given. Note that bash writes its history file when the shell exits, unless
+"history -a" runs in PROMPT_COMMAND.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		newFromHistory = true
+		return runNew(cmd, args)
+	},
+}
+
+var (
+	newFromHistory bool
+	historyShell   string
+	historyLast    int
+)
+
+func init() {
+	prevCmd.Flags().StringVarP(&newTitle, "title", "t", "", "Title of the snippet")
+	prevCmd.Flags().StringSliceVar(&newTags, "tags", nil, "Comma-separated tags, e.g. go,api")
+	prevCmd.Flags().BoolVarP(&newEditor, "editor", "e", false, "Review the snippet in $EDITOR before saving")
+	addHistoryFlags(prevCmd)
+	prevCmd.RegisterFlagCompletionFunc("tags", completeTags)
+}
+
+// addHistoryFlags adds the flags selecting the shell history to read
+func addHistoryFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&historyShell, "shell", "", "Shell whose history to read: bash, zsh or fish (default from $SHELL)")
+	cmd.Flags().IntVarP(&historyLast, "last", "n", 100, "Number of recent distinct commands to choose from")
+	cmd.RegisterFlagCompletionFunc("shell", cobra.FixedCompletions([]cobra.Completion{shellhist.Bash, shellhist.Zsh, shellhist.Fish}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+// pickFromHistory lets the user pick one of the recent commands of the
+// shell history
+func pickFromHistory() (string, error) {
+	shell := historyShell
+	if shell == "" {
+		shell = shellhist.DetectShell()
+	}
+	path, err := shellhist.DefaultFile(shell)
+	if err != nil {
+		return "", err
+	}
+	entries, err := shellhist.ReadFile(shell, path)
+	if err != nil {
+		return "", err
+	}
+
+	// Leave out the snipgo invocation running now, if it is already there
+	var commands []shellhist.Entry
+	for _, entry := range shellhist.Recent(entries, historyLast+1) {
+		if fields := strings.Fields(entry.Command); len(fields) >= 2 && fields[0] == "snipgo" && (fields[1] == "prev" || fields[1] == "new") {
+			continue
+		}
+		commands = append(commands, entry)
+	}
+	if len(commands) > historyLast {
+		commands = commands[:historyLast]
+	}
+	if len(commands) == 0 {
+		return "", fmt.Errorf("%w in %s history (%s)", errNotFound, shell, path)
+	}
+
+	// Present the commands to the picker as snippets, most recent first
+	candidates := make([]*core.Snippet, len(commands))
+	for i, entry := range commands {
+		title, _, _ := strings.Cut(entry.Command, "\n")
+		candidates[i] = &core.Snippet{
+			ID:        strconv.Itoa(i),
+			Title:     title,
+			Body:      entry.Command,
+			Language:  "sh",
+			CreatedAt: entry.Time,
+			UpdatedAt: entry.Time,
+		}
+	}
+
+	search := func(query string) ([]*core.SearchResult, error) {
+		var results []*core.SearchResult
+		if strings.TrimSpace(query) == "" {
+			for _, snippet := range candidates {
+				results = append(results, &core.SearchResult{Snippet: snippet})
+			}
+			return results, nil
+		}
+
+		bodies := make([]string, len(candidates))
+		for i, snippet := range candidates {
+			bodies[i] = snippet.Body
+		}
+		for _, match := range fuzzy.Find(query, bodies) {
+			results = append(results, &core.SearchResult{Snippet: candidates[match.Index], Score: float64(match.Score)})
+		}
+		return results, nil
+	}
+
+	selected, err := pick("", false, search)
+	if err != nil {
+		return "", err
+	}
+	return selected[0].Body, nil
+}
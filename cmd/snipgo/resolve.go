@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"snipgo/internal/core"
+
+	"github.com/mattn/go-isatty"
+)
+
+// maxCandidates is how many candidates an ambiguity error lists
+const maxCandidates = 10
+
+// resolveSnippets returns the snippets args refer to. Without arguments the
+// user picks them; otherwise the arguments are joined into a reference, a
+// full ID, an ID prefix, an exact title or a query, resolved with
+// Manager.Resolve. When the reference is ambiguous, the user picks among the
+// candidates if snipgo runs in a terminal; otherwise it is an error listing
+// them. multi allows picking several snippets.
+func resolveSnippets(args []string, multi bool) ([]*core.Snippet, error) {
+	if len(args) == 0 {
+		return pickSnippets("", multi)
+	}
+
+	snippet, err := manager.Resolve(strings.Join(args, " "))
+	var ambiguous core.ErrAmbiguous
+	if errors.As(err, &ambiguous) {
+		if !isatty.IsTerminal(os.Stdin.Fd()) {
+			return nil, ambiguityError(ambiguous)
+		}
+		return pickFrom(ambiguous.Candidates, multi)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []*core.Snippet{snippet}, nil
+}
+
+// resolveSnippet returns the single snippet args refer to, see
+// resolveSnippets
+func resolveSnippet(args []string) (*core.Snippet, error) {
+	selected, err := resolveSnippets(args, false)
+	if err != nil {
+		return nil, err
+	}
+	return selected[0], nil
+}
+
+// ambiguityError describes an ambiguous reference with its candidates
+func ambiguityError(err core.ErrAmbiguous) error {
+	var sb strings.Builder
+	sb.WriteString(err.Error())
+	sb.WriteString("; pass one of these IDs:")
+	for i, snippet := range err.Candidates {
+		if i == maxCandidates {
+			fmt.Fprintf(&sb, "\n  ... and %d more", len(err.Candidates)-maxCandidates)
+			break
+		}
+		fmt.Fprintf(&sb, "\n  %s  %s", shortID(snippet.ID), snippet.Title)
+	}
+	return errors.New(sb.String())
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var rmCmd = &cobra.Command{
+	Use:     "rm [id | title | query...]",
+	Aliases: []string{"delete"},
+	Short:   "Delete snippets",
+	Long: `Moves snippets to the trash, from which "snipgo trash restore" brings them back.
+
+The argument can be an ID, an ID prefix, an exact title or a search query.
+If it matches several snippets, or there is no argument at all, the picker
+opens to choose which ones to delete. You are asked for confirmation unless --force is given.`,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeSnippets,
+	RunE:              runRm,
+}
+
+var rmForce bool
+
+func init() {
+	rmCmd.Flags().BoolVarP(&rmForce, "force", "f", false, "Delete without asking for confirmation")
+}
+
+func runRm(cmd *cobra.Command, args []string) error {
+	snippets, err := resolveSnippets(args, true)
+	if err != nil {
+		return err
+	}
+
+	if !rmForce {
+		for _, snippet := range snippets {
+			fmt.Printf("  %s  %s\n", shortID(snippet.ID), snippet.Title)
+		}
+		ok, err := confirm(fmt.Sprintf("Move %d snippet(s) to the trash?", len(snippets)))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("cancelled")
+		}
+	}
+
+	for _, snippet := range snippets {
+		if err := manager.Delete(snippet.ID); err != nil {
+			return fmt.Errorf("failed to delete snippet '%s': %w", snippet.Title, err)
+		}
+		fmt.Printf("Moved snippet '%s' to the trash (undo with: snipgo trash restore %s)\n", snippet.Title, shortID(snippet.ID))
+	}
+	return nil
+}
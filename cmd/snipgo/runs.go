@@ -0,0 +1,286 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"snipgo/internal/config"
+	"snipgo/internal/core"
+	"snipgo/internal/runlog"
+	"snipgo/internal/runner"
+
+	"github.com/spf13/cobra"
+)
+
+var runsCmd = &cobra.Command{
+	Use:   "runs [n]",
+	Short: "List the snippets run by exec",
+	Long: `Lists the runs recorded by exec, most recent first, or shows run n in full:
+the rendered command, the placeholder values, the environment variables set
+for it, its working directory, start time, duration and exit code.
+
+Runs are numbered from 1 for the most recent one; rerun accepts the number.
+They are appended to a JSONL file, set with exec_log in the config file
+(~/.local/state/snipgo/runs.jsonl by default, off to disable).`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRuns,
+}
+
+var rerunCmd = &cobra.Command{
+	Use:   "rerun <n>",
+	Short: "Run a command from the run log again",
+	Long: `Runs command n of "snipgo runs" again, exactly as it was run: the same
+rendered body, interpreter, working directory, environment variables and
+timeout, even if the snippet has changed or been deleted since. It is
+confirmed and recorded like a run of exec.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRerun,
+}
+
+var (
+	runsLast    int
+	runsSnippet string
+	runsFailed  bool
+	runsSince   string
+	runsGrep    string
+)
+
+// maxCommandWidth is the width of the command column of the runs table
+const maxCommandWidth = 60
+
+func init() {
+	runsCmd.Flags().IntVarP(&runsLast, "last", "n", 20, "Number of runs to list, 0 for all")
+	runsCmd.Flags().StringVar(&runsSnippet, "snippet", "", "Only runs of the snippet with this ID prefix or title")
+	runsCmd.Flags().BoolVar(&runsFailed, "failed", false, "Only runs with a non-zero exit code")
+	runsCmd.Flags().StringVar(&runsSince, "since", "", "Only runs started since a time (2006-01-02, RFC 3339) or for a duration (24h)")
+	runsCmd.Flags().StringVar(&runsGrep, "grep", "", "Only runs whose command contains this text")
+	runsCmd.RegisterFlagCompletionFunc("snippet", completeSnippets)
+
+	rerunCmd.Flags().BoolVar(&execDryRun, "dry-run", false, "Print the command instead of running it")
+	rerunCmd.Flags().BoolVarP(&execYes, "yes", "y", false, "Run without asking for confirmation, unless the snippet sets confirm: true")
+	rerunCmd.MarkFlagsMutuallyExclusive("dry-run", "yes")
+}
+
+// openRunLog returns the run log set in the configuration
+func openRunLog() (*runlog.Log, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	path := cfg.ExecLogPath()
+	if path == "" {
+		return nil, fmt.Errorf("the run log is turned off, set exec_log in the config file to record runs")
+	}
+	return runlog.New(path), nil
+}
+
+// parseRunNumber parses the number of a run given as an argument
+func parseRunNumber(arg string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid run number %q, expected 1 or more as listed by snipgo runs", arg)
+	}
+	return n, nil
+}
+
+func runRuns(cmd *cobra.Command, args []string) error {
+	log, err := openRunLog()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 1 {
+		n, err := parseRunNumber(args[0])
+		if err != nil {
+			return err
+		}
+		entry, err := log.Get(n)
+		if err != nil {
+			return err
+		}
+		printRun(entry)
+		return nil
+	}
+
+	filter, err := runFilter()
+	if err != nil {
+		return err
+	}
+	entries, err := log.Read()
+	if err != nil {
+		return err
+	}
+
+	var matched []*runlog.Entry
+	for _, entry := range entries {
+		if filter(entry) {
+			matched = append(matched, entry)
+		}
+		if runsLast > 0 && len(matched) == runsLast {
+			break
+		}
+	}
+	if len(matched) == 0 {
+		fmt.Println("No runs found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "#\tStarted\tDuration\tExit\tTitle\tCommand")
+	fmt.Fprintln(w, "-\t-------\t--------\t----\t-----\t-------")
+
+	for _, entry := range matched {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			entry.Number, entry.Start.Local().Format("2006-01-02 15:04:05"), formatDuration(entry.Duration()),
+			formatExit(entry), entry.Title, commandSummary(entry.Command))
+	}
+
+	return w.Flush()
+}
+
+// runFilter returns the filter of runs set by the flags of runs
+func runFilter() (func(*runlog.Entry) bool, error) {
+	var since time.Time
+	if runsSince != "" {
+		t, err := parseSince(runsSince)
+		if err != nil {
+			return nil, err
+		}
+		since = t
+	}
+	ref := strings.ToLower(strings.TrimSpace(runsSnippet))
+
+	return func(entry *runlog.Entry) bool {
+		if runsFailed && entry.ExitCode == 0 {
+			return false
+		}
+		if !since.IsZero() && entry.Start.Before(since) {
+			return false
+		}
+		if ref != "" && !strings.HasPrefix(strings.ToLower(entry.SnippetID), ref) && !strings.EqualFold(entry.Title, ref) {
+			return false
+		}
+		if runsGrep != "" && !strings.Contains(entry.Command, runsGrep) {
+			return false
+		}
+		return true
+	}, nil
+}
+
+// parseSince parses the --since flag: a date, a time or a duration back
+// from now
+func parseSince(s string) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q, expected a date such as 2006-01-02 or a duration such as 24h", s)
+}
+
+// printRun prints a run in full
+func printRun(entry *runlog.Entry) {
+	fmt.Printf("Run:         %d\n", entry.Number)
+	fmt.Printf("Snippet:     %s (%s)\n", entry.Title, entry.SnippetID)
+	fmt.Printf("Started:     %s\n", entry.Start.Local().Format(time.RFC3339))
+	fmt.Printf("Duration:    %s\n", formatDuration(entry.Duration()))
+	fmt.Printf("Exit code:   %s\n", formatExit(entry))
+	fmt.Printf("Directory:   %s\n", entry.Cwd)
+	fmt.Printf("Interpreter: %s\n", entry.Interpreter)
+	if entry.Timeout != "" {
+		fmt.Printf("Timeout:     %s\n", entry.Timeout)
+	}
+	if len(entry.Vars) > 0 {
+		names := make([]string, 0, len(entry.Vars))
+		for name := range entry.Vars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Println("Variables:")
+		for _, name := range names {
+			fmt.Printf("  %s=%s\n", name, entry.Vars[name])
+		}
+	}
+	if len(entry.Env) > 0 {
+		fmt.Println("Environment:")
+		for _, pair := range entry.Env {
+			fmt.Printf("  %s\n", pair)
+		}
+	}
+	fmt.Println()
+	fmt.Println(entry.Command)
+}
+
+// formatDuration rounds a run duration for display
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
+
+// formatExit returns the exit code of a run, marking timeouts
+func formatExit(entry *runlog.Entry) string {
+	if entry.TimedOut {
+		return fmt.Sprintf("%d (timeout)", entry.ExitCode)
+	}
+	return strconv.Itoa(entry.ExitCode)
+}
+
+// commandSummary returns the first line of a command, cut to
+// maxCommandWidth
+func commandSummary(command string) string {
+	line, _, multiline := strings.Cut(command, "\n")
+	if runes := []rune(line); len(runes) > maxCommandWidth {
+		return string(runes[:maxCommandWidth-1]) + "…"
+	}
+	if multiline {
+		return line + " …"
+	}
+	return line
+}
+
+func runRerun(cmd *cobra.Command, args []string) error {
+	n, err := parseRunNumber(args[0])
+	if err != nil {
+		return err
+	}
+	log, err := openRunLog()
+	if err != nil {
+		return err
+	}
+	entry, err := log.Get(n)
+	if err != nil {
+		return err
+	}
+
+	opts := runner.Options{
+		Interpreter: entry.Interpreter,
+		Dir:         entry.Cwd,
+		Env:         entry.Env,
+	}
+	if entry.Timeout != "" {
+		if opts.Timeout, err = runner.ParseTimeout(entry.Timeout); err != nil {
+			return err
+		}
+	}
+
+	// The snippet may have changed or be gone; only confirm: true is taken
+	// from its current version
+	snippet := &core.Snippet{ID: entry.SnippetID, Title: entry.Title, Language: entry.Language}
+	if current, err := manager.GetByID(entry.SnippetID); err == nil {
+		if currentOpts, err := runner.OptionsFromSnippet(current); err == nil {
+			opts.Confirm = currentOpts.Confirm
+		}
+	}
+
+	return execBody(cmd, snippet, entry.Command, entry.Vars, opts)
+}
@@ -2,53 +2,107 @@ package main
 
 import (
 	"fmt"
-
-	"snipgo/internal/core"
+	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
 
 var searchCmd = &cobra.Command{
-	Use:   "search [query]",
+	Use:   "search [query...]",
 	Short: "Search snippets",
-	Long:  "Interactively search and select snippets using fzf. If query is provided, filters results first.",
-	Args:  cobra.MaximumNArgs(1),
-	RunE:  runSearch,
+	Long: `Interactively search and select snippets. If query is provided, filters results first.
+
+The picker shows a live preview of the snippet under the cursor. Press Tab to
+mark several snippets; their bodies are printed one after another.
+
+Queries combine words, "exact phrases" and filters such as tag:docker,
+lang:yaml, is:fav, created:>2025-01-01 and updated:<2025-06-01. Terms must
+all match; use OR to match either side, -term or NOT term to exclude, and
+parentheses to group.
+
+With --fill, placeholders are prompted for, or taken from --var, and the
+bodies are printed filled in; the widgets of "snipgo shell-init" use it.
+
+With --no-interactive, --output or --format, the matching snippets are
+printed best match first instead, as a table or in the chosen format. Exits
+with status 1 if nothing matches or nothing is selected.`,
+	Args: cobra.ArbitraryArgs,
+	RunE: runSearch,
+}
+
+var (
+	searchNoInteractive bool
+	searchFill          bool
+	searchVars          []string
+)
+
+func init() {
+	searchCmd.Flags().BoolVar(&searchNoInteractive, "no-interactive", false, "Print the ranked results instead of opening the picker")
+	searchCmd.Flags().BoolVar(&searchFill, "fill", false, "Fill in the placeholders of the selected snippets")
+	searchCmd.Flags().StringArrayVar(&searchVars, "var", nil, "Set a placeholder value (name=value) for --fill, may be repeated")
+	addOutputFlags(searchCmd)
+	searchCmd.MarkFlagsMutuallyExclusive("fill", "no-interactive")
+	searchCmd.MarkFlagsMutuallyExclusive("fill", "output")
+	searchCmd.MarkFlagsMutuallyExclusive("fill", "format")
 }
 
 func runSearch(cmd *cobra.Command, args []string) error {
-	var snippets []*core.Snippet
-
-	if len(args) > 0 {
-		// Search with query
-		query := args[0]
-		results := manager.Search(query)
-		if len(results) == 0 {
-			fmt.Printf("No snippets found for query: %s\n", query)
-			return nil
-		}
-		// Convert SearchResult to Snippet
-		snippets = make([]*core.Snippet, len(results))
-		for i, result := range results {
-			snippets[i] = result.Snippet
+	query := strings.Join(args, " ")
+	if searchNoInteractive || machineOutput() {
+		return printSearchResults(query)
+	}
+
+	// Select snippets, starting from the query if any
+	selected, err := pickSnippets(query, true)
+	if err != nil {
+		return err
+	}
+
+	preset, err := parseVarFlags(searchVars)
+	if err != nil {
+		return err
+	}
+
+	// Output bodies to stdout
+	for i, snippet := range selected {
+		body := snippet.Body
+		if searchFill {
+			if body, _, err = fillParams(snippet, preset); err != nil {
+				return err
+			}
 		}
-	} else {
-		// No query, use all snippets
-		snippets = manager.GetAll()
-		if len(snippets) == 0 {
-			fmt.Println("No snippets found.")
-			return nil
+		if i > 0 {
+			fmt.Println()
 		}
+		fmt.Print(body)
 	}
+	return nil
+}
 
-	// Use fzf to select
-	selected, err := selectSnippetWithFzf(snippets)
+// printSearchResults prints the results of query, best match first
+func printSearchResults(query string) error {
+	results, err := searchAll(query)
 	if err != nil {
 		return err
 	}
+	records := snippetRecords(results)
 
-	// Output body to stdout
-	fmt.Print(selected.Body)
+	if machineOutput() {
+		if err := writeRecords(os.Stdout, records, false); err != nil {
+			return err
+		}
+	} else if len(records) > 0 {
+		if err := writeTable(os.Stdout, records, strings.TrimSpace(query) != ""); err != nil {
+			return err
+		}
+	}
+
+	if len(records) == 0 {
+		if strings.TrimSpace(query) == "" {
+			return errNotFound
+		}
+		return fmt.Errorf("%w for query: %s", errNotFound, query)
+	}
 	return nil
 }
-
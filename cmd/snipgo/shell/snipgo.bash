@@ -0,0 +1,22 @@
+# snipgo shell integration for bash, load it with:
+#   eval "$(snipgo shell-init bash)"
+#
+# Ctrl+S opens the snipgo picker, searching for the text before the cursor,
+# and replaces that text with the chosen snippet, its placeholders filled in.
+# Bind __snipgo_widget to another key with: bind -x '"\C-g": __snipgo_widget'
+
+__snipgo_widget() {
+  local query="${READLINE_LINE:0:READLINE_POINT}"
+  local snippet
+  snippet="$(snipgo search --fill -- "$query" </dev/tty)" || return
+  READLINE_LINE="${snippet}${READLINE_LINE:READLINE_POINT}"
+  READLINE_POINT=${#snippet}
+}
+
+# Ctrl+S stops the terminal output by default, free it for the binding
+if [[ -t 0 ]]; then
+  stty -ixon
+fi
+
+bind -m emacs-standard -x '"\C-s": __snipgo_widget'
+bind -m vi-insert -x '"\C-s": __snipgo_widget'
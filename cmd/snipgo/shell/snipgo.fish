@@ -0,0 +1,24 @@
+# snipgo shell integration for fish, load it with:
+#   snipgo shell-init fish | source
+#
+# Ctrl+S opens the snipgo picker, searching for the text before the cursor,
+# and replaces that text with the chosen snippet, its placeholders filled in.
+# Bind __snipgo_widget to another key with: bind \cg __snipgo_widget
+
+function __snipgo_widget
+    set -l query (commandline --cut-at-cursor | string collect)
+    set -l line (commandline | string collect)
+    set -l after (string sub --start (math (string length -- "$query") + 1) -- "$line")
+
+    # set returns the status of the command substitution
+    set -l snippet (snipgo search --fill -- "$query" </dev/tty)
+    if test $status -eq 0
+        set snippet (string join \n -- $snippet)
+        commandline --replace -- "$snippet$after"
+        commandline --cursor (string length -- "$snippet")
+    end
+    commandline --function repaint
+end
+
+bind \cs __snipgo_widget
+bind -M insert \cs __snipgo_widget
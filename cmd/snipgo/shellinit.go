@@ -0,0 +1,42 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// shellScripts holds the widgets printed by shell-init, one file per shell
+//
+//go:embed shell/snipgo.bash shell/snipgo.zsh shell/snipgo.fish
+var shellScripts embed.FS
+
+var shellInitCmd = &cobra.Command{
+	Use:   "shell-init <bash|zsh|fish>",
+	Short: "Print a shell widget that inserts snippets into the command line",
+	Long: `Prints a script for your shell that binds Ctrl+S to a widget: it opens the
+picker, searching for the text before the cursor, prompts for the snippet's
+placeholders and puts the result on the command line, ready to edit and run.
+
+Load it from your shell's startup file:
+  bash (~/.bashrc):                 eval "$(snipgo shell-init bash)"
+  zsh (~/.zshrc):                   eval "$(snipgo shell-init zsh)"
+  fish (~/.config/fish/config.fish): snipgo shell-init fish | source
+
+The script also turns off terminal flow control, which claims Ctrl+S. To
+use another key, bind the widget function named in the script yourself.`,
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: []string{"bash", "zsh", "fish"},
+	RunE:      runShellInit,
+}
+
+func runShellInit(cmd *cobra.Command, args []string) error {
+	script, err := shellScripts.ReadFile("shell/snipgo." + args[0])
+	if err != nil {
+		return fmt.Errorf("unsupported shell %q (expected bash, zsh or fish)", args[0])
+	}
+	_, err = os.Stdout.Write(script)
+	return err
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"snipgo/internal/core"
+
+	"github.com/spf13/cobra"
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show [id | title | query...]",
+	Short: "Show a snippet",
+	Long: `Prints a snippet as its Markdown file, frontmatter included.
+
+The snippet is named by its ID, an ID prefix, its exact title or a search
+query, and selected interactively if there is no argument or several
+snippets match.
+
+Use --output json, yaml, csv or tsv for machine-readable output, or --format
+to print it with a Go template, e.g. --format '{{.Body}}'. Exits with status
+1 if no snippet matches.`,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeSnippets,
+	RunE:              runShow,
+}
+
+func init() {
+	addOutputFlags(showCmd)
+}
+
+func runShow(cmd *cobra.Command, args []string) error {
+	snippet, err := resolveSnippet(args)
+	if err != nil {
+		return err
+	}
+
+	if machineOutput() {
+		return writeRecords(os.Stdout, []*snippetRecord{newSnippetRecord(snippet)}, true)
+	}
+
+	content, err := core.SerializeFrontmatter(snippet)
+	if err != nil {
+		return fmt.Errorf("failed to serialize snippet: %w", err)
+	}
+	if _, err := os.Stdout.Write(content); err != nil {
+		return err
+	}
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		_, err = os.Stdout.WriteString("\n")
+	}
+	return err
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"snipgo/internal/gitsync"
+
+	"github.com/spf13/cobra"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Sync snippets with a git remote",
+	Long: `Commits pending changes in the data directory, pulls the remote branch with
+rebase and pushes the result.
+
+Snippets changed on both sides are merged: tags are combined, and when both
+sides changed the same field the most recently updated version wins. Bodies
+are merged line by line; edits to the same lines are left with conflict
+markers for you to resolve.
+
+Once the data directory is a git repository (see sync init), every change
+made by snipgo is committed automatically.`,
+	Args: cobra.NoArgs,
+	RunE: runSync,
+}
+
+var syncInitCmd = &cobra.Command{
+	Use:   "init [remote-url]",
+	Short: "Make the data directory a git repository",
+	Long:  "Makes the data directory a git repository, if it isn't one yet, commits the snippets in it and sets the remote to sync with",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runSyncInit,
+}
+
+func init() {
+	syncCmd.AddCommand(syncInitCmd)
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	dir := manager.Storage().GetSnippetsDir()
+	repo, err := gitsync.Open(dir)
+	if errors.Is(err, gitsync.ErrNotRepository) {
+		return fmt.Errorf("%s is not a git repository, run: snipgo sync init <remote-url>", dir)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Keep other snipgo processes from writing during the rebase
+	unlock, err := manager.Storage().Lock()
+	if err != nil {
+		return fmt.Errorf("failed to lock storage: %w", err)
+	}
+	result, err := repo.Sync()
+	unlock()
+	if err != nil {
+		return fmt.Errorf("failed to sync: %w", err)
+	}
+
+	for _, path := range result.Merged {
+		fmt.Printf("Merged changes to %s\n", path)
+	}
+	for _, path := range result.Conflicts {
+		fmt.Printf("Conflicting edits in %s are marked in its body, edit the snippet to resolve them\n", path)
+	}
+	if result.Pulled == 0 && result.Pushed == 0 {
+		fmt.Println("Already up to date")
+	} else {
+		fmt.Printf("Pulled %d and pushed %d commits\n", result.Pulled, result.Pushed)
+	}
+	return nil
+}
+
+func runSyncInit(cmd *cobra.Command, args []string) error {
+	var remoteURL string
+	if len(args) > 0 {
+		remoteURL = args[0]
+	}
+
+	unlock, err := manager.Storage().Lock()
+	if err != nil {
+		return fmt.Errorf("failed to lock storage: %w", err)
+	}
+	repo, err := gitsync.Init(manager.Storage().GetSnippetsDir(), remoteURL)
+	unlock()
+	if err != nil {
+		return fmt.Errorf("failed to initialize repository: %w", err)
+	}
+
+	fmt.Printf("Snippets in %s are now kept in git\n", repo.Dir())
+	if remoteURL != "" {
+		fmt.Printf("Run 'snipgo sync' to sync with %s\n", remoteURL)
+	}
+	return nil
+}
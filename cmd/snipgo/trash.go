@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"snipgo/internal/core"
+
+	"github.com/spf13/cobra"
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage deleted snippets",
+	Long:  "List, restore or permanently delete the snippets removed with rm",
+}
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List deleted snippets",
+	Args:  cobra.NoArgs,
+	RunE:  runTrashList,
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <id-prefix>...",
+	Short: "Restore deleted snippets",
+	Long:  "Moves snippets out of the trash, back to the file they were deleted from",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runTrashRestore,
+}
+
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "Permanently delete the snippets in the trash",
+	Args:  cobra.NoArgs,
+	RunE:  runTrashEmpty,
+}
+
+var trashEmptyForce bool
+
+func init() {
+	trashEmptyCmd.Flags().BoolVarP(&trashEmptyForce, "force", "f", false, "Empty the trash without asking for confirmation")
+
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashEmptyCmd)
+}
+
+func runTrashList(cmd *cobra.Command, args []string) error {
+	entries, err := manager.ListTrash()
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("Trash is empty.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "ID\tTitle\tDeleted")
+	fmt.Fprintln(w, "---\t-----\t-------")
+
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\n",
+			shortID(entry.ID), entry.Title, entry.DeletedAt.Local().Format("2006-01-02 15:04"))
+	}
+
+	return w.Flush()
+}
+
+func runTrashRestore(cmd *cobra.Command, args []string) error {
+	entries, err := manager.ListTrash()
+	if err != nil {
+		return err
+	}
+
+	for _, prefix := range args {
+		entry, err := findTrashEntry(entries, prefix)
+		if err != nil {
+			return err
+		}
+
+		snippet, err := manager.Restore(entry.ID)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Restored snippet '%s'\n", snippet.Title)
+	}
+	return nil
+}
+
+// findTrashEntry returns the trash entry whose snippet ID starts with prefix
+func findTrashEntry(entries []*core.TrashEntry, prefix string) (*core.TrashEntry, error) {
+	prefix = strings.ToUpper(prefix)
+
+	var found *core.TrashEntry
+	for _, entry := range entries {
+		if !strings.HasPrefix(strings.ToUpper(entry.ID), prefix) {
+			continue
+		}
+		if found != nil && found.ID != entry.ID {
+			return nil, fmt.Errorf("ID prefix %s is ambiguous, matches %s and %s", prefix, found.ID, entry.ID)
+		}
+		if found == nil {
+			// Entries are sorted most recent first
+			found = entry
+		}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("no snippet with ID prefix %s in trash", prefix)
+	}
+	return found, nil
+}
+
+func runTrashEmpty(cmd *cobra.Command, args []string) error {
+	if !trashEmptyForce {
+		entries, err := manager.ListTrash()
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			fmt.Println("Trash is empty.")
+			return nil
+		}
+
+		ok, err := confirm(fmt.Sprintf("Permanently delete %d snippet(s) in the trash?", len(entries)))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("cancelled")
+		}
+	}
+
+	n, err := manager.EmptyTrash()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Permanently deleted %d snippet(s)\n", n)
+	return nil
+}
@@ -34,9 +34,13 @@ vi.mock('./bridge', () => ({
     GetSnippet: vi.fn(),
     SearchSnippets: vi.fn(),
     SaveSnippet: vi.fn(),
+    ResolveSnippetConflict: vi.fn(),
     DeleteSnippet: vi.fn(),
+    RestoreSnippet: vi.fn(),
     ReloadSnippets: vi.fn(),
     CopyToClipboard: vi.fn(),
+    GetSnippetParams: vi.fn(),
+    RenderSnippet: vi.fn(),
   },
 }));
 
@@ -64,11 +68,16 @@ describe('App', () => {
       const snippet = mockSnippets.find(s => s.id === id);
       return Promise.resolve(snippet!);
     });
-    vi.mocked(app.SearchSnippets).mockResolvedValue([mockSnippets[0]]);
-    vi.mocked(app.SaveSnippet).mockResolvedValue(undefined);
+    vi.mocked(app.SearchSnippets).mockResolvedValue([{ snippet: mockSnippets[0], score: 1 }]);
+    vi.mocked(app.SaveSnippet).mockImplementation((s: Snippet) => Promise.resolve(s));
     vi.mocked(app.DeleteSnippet).mockResolvedValue(undefined);
+    vi.mocked(app.RestoreSnippet).mockImplementation((id: string) => {
+      const snippet = mockSnippets.find(s => s.id === id);
+      return Promise.resolve(snippet!);
+    });
     vi.mocked(app.ReloadSnippets).mockResolvedValue(undefined);
     vi.mocked(app.CopyToClipboard).mockResolvedValue(undefined);
+    vi.mocked(app.GetSnippetParams).mockResolvedValue([]);
   });
 
   describe('기본 렌더링', () => {
@@ -206,5 +215,36 @@ describe('App', () => {
       });
     });
   });
+
+  describe('삭제 되돌리기', () => {
+    it('삭제 후 Undo를 누르면 RestoreSnippet으로 복원하고 다시 선택한다', async () => {
+      const { app } = await import('./bridge');
+      const user = userEvent.setup();
+      render(<App />);
+
+      await waitFor(() => {
+        expect(screen.getByText('First Snippet')).toBeInTheDocument();
+      });
+      await user.click(screen.getByText('First Snippet'));
+      await waitFor(() => {
+        expect(screen.getByDisplayValue('First Snippet')).toBeInTheDocument();
+      });
+
+      await user.click(screen.getByRole('button', { name: 'Delete' }));
+
+      await waitFor(() => {
+        expect(app.DeleteSnippet).toHaveBeenCalledWith('1');
+        expect(screen.getByText('Moved "First Snippet" to trash')).toBeInTheDocument();
+      });
+
+      await user.click(screen.getByRole('button', { name: 'Undo' }));
+
+      await waitFor(() => {
+        expect(app.RestoreSnippet).toHaveBeenCalledWith('1');
+        expect(screen.getByDisplayValue('First Snippet')).toBeInTheDocument();
+        expect(screen.queryByText('Moved "First Snippet" to trash')).not.toBeInTheDocument();
+      });
+    });
+  });
 });
 
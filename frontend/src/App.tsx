@@ -1,5 +1,6 @@
-import { useState, useRef, useCallback } from 'react';
-import { Snippet } from './types';
+import { useState, useRef, useCallback, useEffect } from 'react';
+import { EventsOn } from '../wailsjs/runtime/runtime';
+import { Snippet, SnippetChangeEvent } from './types';
 import { SnippetList } from './components/SnippetList';
 import { SnippetEditor } from './components/SnippetEditor';
 import { app } from './bridge';
@@ -8,12 +9,35 @@ function App() {
   const [selectedSnippet, setSelectedSnippet] = useState<Snippet | null>(null);
   const [searchQuery, setSearchQuery] = useState('');
   const [listRefreshKey, setListRefreshKey] = useState(0);
+  const [lastDeleted, setLastDeleted] = useState<Snippet | null>(null); // 되돌리기 대상
   const isDirtyRef = useRef(false);
 
   const handleDirtyChange = useCallback((dirty: boolean) => {
     isDirtyRef.current = dirty;
   }, []);
 
+  // 외부에서 파일이 변경되면 목록과 선택된 스니펫 갱신
+  useEffect(() => {
+    const off = EventsOn('snippets:changed', (event: SnippetChangeEvent) => {
+      setListRefreshKey((k) => k + 1);
+      if (isDirtyRef.current) {
+        return; // 편집 중인 내용은 덮어쓰지 않음
+      }
+      setSelectedSnippet((current) => {
+        if (!current || current.id !== event.id) {
+          return current;
+        }
+        if (event.type === 'deleted') {
+          return null;
+        }
+        return event.snippet ?? current;
+      });
+    });
+    return () => {
+      off?.();
+    };
+  }, []);
+
   const handleSelectSnippet = async (snippet: Snippet) => {
     if (isDirtyRef.current) {
       const result = confirm('저장하지 않은 변경사항이 있습니다. 저장하지 않고 이동하시겠습니까?');
@@ -41,11 +65,36 @@ function App() {
     setListRefreshKey((k) => k + 1);
   }, []);
 
-  const handleDelete = () => {
+  const handleDelete = (deleted: Snippet) => {
     setSelectedSnippet(null);
+    setLastDeleted(deleted);
     setListRefreshKey((k) => k + 1); // 목록 갱신
   };
 
+  // 삭제 알림은 잠시 후 자동으로 닫힘
+  useEffect(() => {
+    if (!lastDeleted) {
+      return;
+    }
+    const timer = setTimeout(() => setLastDeleted(null), 10000);
+    return () => clearTimeout(timer);
+  }, [lastDeleted]);
+
+  const handleUndoDelete = async () => {
+    if (!lastDeleted) return;
+    try {
+      const restored = await app.RestoreSnippet(lastDeleted.id);
+      setLastDeleted(null);
+      setSelectedSnippet(restored);
+      setListRefreshKey((k) => k + 1);
+    } catch (err) {
+      alert(
+        'Failed to restore snippet: ' +
+          (err instanceof Error ? err.message : 'Unknown error')
+      );
+    }
+  };
+
   return (
     <div className="h-screen flex flex-col bg-gray-50">
       {/* Header */}
@@ -87,6 +136,22 @@ function App() {
           />
         </main>
       </div>
+
+      {/* 삭제 되돌리기 */}
+      {lastDeleted && (
+        <div
+          role="status"
+          className="fixed bottom-6 left-1/2 -translate-x-1/2 flex items-center gap-4 px-4 py-3 bg-gray-800 text-white rounded-lg shadow-lg"
+        >
+          <span>Moved "{lastDeleted.title}" to trash</span>
+          <button
+            onClick={handleUndoDelete}
+            className="font-semibold text-blue-300 hover:text-blue-200"
+          >
+            Undo
+          </button>
+        </div>
+      )}
     </div>
   );
 }
@@ -1,7 +1,7 @@
 // Import Wails generated bindings
 import * as WailsApp from '../wailsjs/go/app/App';
 import { core } from '../wailsjs/go/models';
-import { Snippet } from './types';
+import { Snippet, ConflictResolution, ConflictStrategy, SnippetParam, SearchResult, TrashEntry, Version } from './types';
 
 // Convert Wails core.Snippet to our Snippet type
 function convertSnippet(wailsSnippet: core.Snippet): Snippet {
@@ -18,6 +18,8 @@ function convertSnippet(wailsSnippet: core.Snippet): Snippet {
       ? wailsSnippet.updated_at
       : new Date(wailsSnippet.updated_at).toISOString(),
     body: wailsSnippet.body,
+    revision: wailsSnippet.revision,
+    extra: wailsSnippet.extra,
   };
 }
 
@@ -32,6 +34,8 @@ function convertToWailsSnippet(snippet: Snippet): core.Snippet {
     created_at: snippet.created_at,
     updated_at: snippet.updated_at,
     body: snippet.body,
+    revision: snippet.revision ?? '',
+    extra: snippet.extra,
   });
 }
 
@@ -39,9 +43,17 @@ function convertToWailsSnippet(snippet: Snippet): core.Snippet {
 export interface App {
   GetAllSnippets(): Promise<Snippet[]>;
   GetSnippet(id: string): Promise<Snippet>;
-  SaveSnippet(snippet: Snippet): Promise<void>;
+  SaveSnippet(snippet: Snippet): Promise<Snippet>;
+  ResolveSnippetConflict(snippet: Snippet, strategy: ConflictStrategy): Promise<ConflictResolution>;
   DeleteSnippet(id: string): Promise<void>;
-  SearchSnippets(query: string): Promise<Snippet[]>;
+  ListTrash(): Promise<TrashEntry[]>;
+  RestoreSnippet(id: string): Promise<Snippet>;
+  EmptyTrash(): Promise<number>;
+  GetSnippetHistory(id: string): Promise<Version[]>;
+  RevertSnippet(id: string, rev: string): Promise<Snippet>;
+  SearchSnippets(query: string): Promise<SearchResult[]>;
+  GetSnippetParams(body: string): Promise<SnippetParam[]>;
+  RenderSnippet(body: string, values: Record<string, string>): Promise<string>;
   CopyToClipboard(text: string): Promise<void>;
   ReloadSnippets(): Promise<void>;
 }
@@ -57,13 +69,63 @@ export const app: App = {
     return convertSnippet(result);
   },
   SaveSnippet: async (snippet: Snippet) => {
-    await WailsApp.SaveSnippet(convertToWailsSnippet(snippet));
+    const result = await WailsApp.SaveSnippet(convertToWailsSnippet(snippet));
+    return convertSnippet(result);
+  },
+  ResolveSnippetConflict: async (snippet: Snippet, strategy: ConflictStrategy) => {
+    const result = await WailsApp.ResolveSnippetConflict(convertToWailsSnippet(snippet), strategy);
+    return {
+      snippet: convertSnippet(result.snippet),
+      conflicts: result.conflicts ?? [],
+      saved: result.saved,
+    };
   },
   DeleteSnippet: WailsApp.DeleteSnippet,
+  ListTrash: async () => {
+    const result = await WailsApp.ListTrash();
+    return (result ?? []).map((entry) => ({
+      id: entry.id,
+      title: entry.title,
+      path: entry.path,
+      deleted_at: typeof entry.deleted_at === 'string'
+        ? entry.deleted_at
+        : new Date(entry.deleted_at).toISOString(),
+    }));
+  },
+  RestoreSnippet: async (id: string) => {
+    const result = await WailsApp.RestoreSnippet(id);
+    return convertSnippet(result);
+  },
+  EmptyTrash: WailsApp.EmptyTrash,
+  GetSnippetHistory: async (id: string) => {
+    const result = await WailsApp.GetSnippetHistory(id);
+    return (result ?? []).map((v) => ({
+      rev: v.rev,
+      number: v.number,
+      saved_at: typeof v.saved_at === 'string'
+        ? v.saved_at
+        : new Date(v.saved_at).toISOString(),
+      snippet: convertSnippet(v.snippet),
+    }));
+  },
+  RevertSnippet: async (id: string, rev: string) => {
+    const result = await WailsApp.RevertSnippet(id, rev);
+    return convertSnippet(result);
+  },
   SearchSnippets: async (query: string) => {
     const result = await WailsApp.SearchSnippets(query);
-    return result.map(convertSnippet);
+    return result.map((r) => ({
+      snippet: convertSnippet(r.snippet),
+      score: r.score,
+      matches: r.matches as SearchResult['matches'],
+      excerpt: r.excerpt,
+    }));
+  },
+  GetSnippetParams: async (body: string) => {
+    const result = await WailsApp.GetSnippetParams(body);
+    return result ?? [];
   },
+  RenderSnippet: WailsApp.RenderSnippet,
   CopyToClipboard: WailsApp.CopyToClipboard,
   ReloadSnippets: WailsApp.ReloadSnippets,
 };
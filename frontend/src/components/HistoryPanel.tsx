@@ -0,0 +1,94 @@
+import { useState, useEffect } from "react";
+import { Version } from "../types";
+import { app } from "../bridge";
+
+interface HistoryPanelProps {
+  snippetId: string;
+  onRevert: (rev: string) => void;
+  onClose: () => void;
+}
+
+// 스니펫 이전 버전 타임라인. 버전을 선택하면 내용을 미리 보고 되돌릴 수 있음
+export function HistoryPanel({ snippetId, onRevert, onClose }: HistoryPanelProps) {
+  const [versions, setVersions] = useState<Version[] | null>(null);
+  const [selected, setSelected] = useState<Version | null>(null);
+  const [error, setError] = useState<string | null>(null);
+
+  useEffect(() => {
+    let cancelled = false;
+    app
+      .GetSnippetHistory(snippetId)
+      .then((result) => {
+        if (cancelled) return;
+        setVersions(result);
+        setSelected(result[0] ?? null);
+      })
+      .catch((err) => {
+        if (!cancelled) {
+          setError(err instanceof Error ? err.message : String(err));
+        }
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [snippetId]);
+
+  return (
+    <div className="fixed inset-0 bg-black/30 flex items-center justify-center z-10">
+      <div
+        role="dialog"
+        aria-label="History"
+        className="bg-white rounded-lg shadow-lg p-6 w-[48rem] max-h-[80vh] flex flex-col gap-3"
+      >
+        <h2 className="text-lg font-semibold">History</h2>
+        {error && <p className="text-sm text-red-600">{error}</p>}
+        {versions && versions.length === 0 && (
+          <p className="text-sm text-gray-500">No previous versions</p>
+        )}
+        {versions && versions.length > 0 && (
+          <div className="flex gap-4 min-h-0 flex-1">
+            <ul className="w-56 overflow-y-auto border-r border-gray-200 pr-2">
+              {versions.map((version) => (
+                <li key={version.rev}>
+                  <button
+                    onClick={() => setSelected(version)}
+                    className={`w-full text-left px-2 py-1 rounded text-sm ${
+                      selected?.rev === version.rev
+                        ? "bg-blue-100 text-blue-800"
+                        : "hover:bg-gray-100"
+                    }`}
+                  >
+                    <span className="block">
+                      {new Date(version.saved_at).toLocaleString()}
+                    </span>
+                    <span className="block text-xs text-gray-500 truncate">
+                      #{version.number} {version.snippet.title}
+                    </span>
+                  </button>
+                </li>
+              ))}
+            </ul>
+            <pre className="flex-1 overflow-auto p-2 bg-gray-50 rounded font-mono text-sm whitespace-pre-wrap">
+              {selected?.snippet.body}
+            </pre>
+          </div>
+        )}
+        <div className="flex justify-end gap-2 mt-2">
+          <button
+            onClick={onClose}
+            className="px-4 py-2 bg-gray-100 text-gray-600 rounded hover:bg-gray-200"
+          >
+            Close
+          </button>
+          <button
+            onClick={() => selected && onRevert(selected.rev)}
+            disabled={!selected}
+            className="px-4 py-2 bg-blue-500 text-white rounded hover:bg-blue-600 disabled:opacity-50"
+          >
+            Revert
+          </button>
+        </div>
+      </div>
+    </div>
+  );
+}
@@ -0,0 +1,60 @@
+import { useState } from "react";
+import { SnippetParam } from "../types";
+
+interface ParamsFormProps {
+  params: SnippetParam[];
+  onSubmit: (values: Record<string, string>) => void;
+  onCancel: () => void;
+}
+
+// 스니펫 플레이스홀더(<name=default>, {{name:default}}) 값 입력 폼
+export function ParamsForm({ params, onSubmit, onCancel }: ParamsFormProps) {
+  const [values, setValues] = useState<Record<string, string>>(() =>
+    Object.fromEntries(params.map((p) => [p.name, p.default]))
+  );
+
+  const handleSubmit = (e: React.FormEvent) => {
+    e.preventDefault();
+    onSubmit(values);
+  };
+
+  return (
+    <div className="fixed inset-0 bg-black/30 flex items-center justify-center z-10">
+      <form
+        onSubmit={handleSubmit}
+        className="bg-white rounded-lg shadow-lg p-6 w-96 flex flex-col gap-3"
+      >
+        <h2 className="text-lg font-semibold">Fill in parameters</h2>
+        {params.map((param, idx) => (
+          <label key={param.name} className="flex flex-col text-sm">
+            <span className="mb-1 text-gray-700">{param.name}</span>
+            <input
+              type="text"
+              value={values[param.name] ?? ""}
+              autoFocus={idx === 0}
+              onChange={(e) =>
+                setValues((v) => ({ ...v, [param.name]: e.target.value }))
+              }
+              className="px-2 py-1 border border-gray-300 rounded"
+            />
+          </label>
+        ))}
+        <div className="flex justify-end gap-2 mt-2">
+          <button
+            type="button"
+            onClick={onCancel}
+            className="px-4 py-2 bg-gray-100 text-gray-600 rounded hover:bg-gray-200"
+          >
+            Cancel
+          </button>
+          <button
+            type="submit"
+            className="px-4 py-2 bg-blue-500 text-white rounded hover:bg-blue-600"
+          >
+            Copy
+          </button>
+        </div>
+      </form>
+    </div>
+  );
+}
@@ -7,10 +7,42 @@ import { Snippet } from "../types";
 // Mock the bridge module
 vi.mock("../bridge", () => ({
   app: {
-    SaveSnippet: vi.fn().mockResolvedValue(undefined),
+    SaveSnippet: vi.fn().mockImplementation((s: Snippet) => Promise.resolve(s)),
+    ResolveSnippetConflict: vi.fn(),
     DeleteSnippet: vi.fn().mockResolvedValue(undefined),
     ReloadSnippets: vi.fn().mockResolvedValue(undefined),
     CopyToClipboard: vi.fn().mockResolvedValue(undefined),
+    GetSnippetParams: vi.fn().mockResolvedValue([]),
+    RenderSnippet: vi.fn((body: string) => Promise.resolve(body)),
+    GetSnippetHistory: vi.fn().mockResolvedValue([
+      {
+        rev: "20250101_000000.000000",
+        number: 1,
+        saved_at: "2025-01-01T00:00:00Z",
+        snippet: {
+          id: "test-id",
+          title: "Test Snippet",
+          tags: ["tag1", "tag2"],
+          language: "javascript",
+          is_favorite: false,
+          created_at: "2025-01-01T00:00:00Z",
+          updated_at: "2024-12-31T00:00:00Z",
+          body: 'console.log("old");',
+        },
+      },
+    ]),
+    RevertSnippet: vi.fn().mockImplementation((id: string) =>
+      Promise.resolve({
+        id,
+        title: "Test Snippet",
+        tags: ["tag1", "tag2"],
+        language: "javascript",
+        is_favorite: false,
+        created_at: "2025-01-01T00:00:00Z",
+        updated_at: "2025-01-02T00:00:00Z",
+        body: 'console.log("old");',
+      })
+    ),
   },
 }));
 
@@ -199,4 +231,32 @@ describe("SnippetEditor", () => {
       expect(screen.getByText("★ Favorite")).toBeInTheDocument();
     });
   });
+
+  describe("버전 기록", () => {
+    it("History에서 이전 버전을 선택해 되돌린다", async () => {
+      const { app } = await import("../bridge");
+      const user = userEvent.setup();
+      render(<SnippetEditor {...defaultProps} />);
+
+      await user.click(screen.getByText("History"));
+
+      await waitFor(() => {
+        expect(screen.getByText('console.log("old");')).toBeInTheDocument();
+      });
+      expect(app.GetSnippetHistory).toHaveBeenCalledWith("test-id");
+
+      await user.click(screen.getByText("Revert"));
+
+      await waitFor(() => {
+        expect(app.RevertSnippet).toHaveBeenCalledWith(
+          "test-id",
+          "20250101_000000.000000"
+        );
+        expect(defaultProps.onSave).toHaveBeenCalledWith(
+          expect.objectContaining({ body: 'console.log("old");' })
+        );
+      });
+      expect(screen.queryByRole("dialog")).not.toBeInTheDocument();
+    });
+  });
 });
@@ -6,13 +6,33 @@ import { yaml } from "@codemirror/lang-yaml";
 import { json } from "@codemirror/lang-json";
 import { markdown } from "@codemirror/lang-markdown";
 import type { Extension } from "@codemirror/state";
-import { Snippet } from "../types";
+import { Snippet, ConflictStrategy, SnippetParam } from "../types";
 import { app } from "../bridge";
+import { ParamsForm } from "./ParamsForm";
+import { HistoryPanel } from "./HistoryPanel";
+
+// 디스크에서 동시에 수정된 경우 SaveSnippet 에러 메시지는 "conflict:"로 시작
+function isConflictError(err: unknown): boolean {
+  const message = err instanceof Error ? err.message : String(err);
+  return message.startsWith("conflict:");
+}
+
+function askConflictStrategy(): ConflictStrategy | null {
+  const answer = prompt(
+    "이 스니펫이 외부에서 수정되었습니다.\nmine(내 변경 유지), theirs(외부 변경 적용), merge(병합) 중 선택하세요:",
+    "merge"
+  );
+  const strategy = answer?.trim().toLowerCase();
+  if (strategy === "mine" || strategy === "theirs" || strategy === "merge") {
+    return strategy;
+  }
+  return null;
+}
 
 interface SnippetEditorProps {
   snippet: Snippet | null;
   onSave: (updatedSnippet: Snippet) => void;
-  onDelete: () => void;
+  onDelete: (deleted: Snippet) => void; // 휴지통으로 이동된 snippet
   onDirtyChange?: (isDirty: boolean) => void;
   onListRefresh?: () => void;
 }
@@ -41,6 +61,9 @@ export function SnippetEditor({
   const [body, setBody] = useState("");
   const [rawMode, setRawMode] = useState(false);
   const [rawContent, setRawContent] = useState("");
+  const [revision, setRevision] = useState<string | undefined>(undefined);
+  const [copyParams, setCopyParams] = useState<SnippetParam[] | null>(null);
+  const [showHistory, setShowHistory] = useState(false);
 
   // isDirty 계산 (title, body, language만 - tag/favorite는 즉시 저장됨)
   const isDirty = useMemo(() => {
@@ -65,6 +88,7 @@ export function SnippetEditor({
       setLanguage(snippet.language);
       setIsFavorite(snippet.is_favorite);
       setBody(snippet.body);
+      setRevision(snippet.revision);
     } else {
       setTitle("");
       setTags([]);
@@ -72,9 +96,52 @@ export function SnippetEditor({
       setLanguage("");
       setIsFavorite(false);
       setBody("");
+      setRevision(undefined);
     }
   }, [snippet]);
 
+  // 저장 충돌 해결. 저장되었으면 저장된 스니펫, 아니면 null 반환
+  const resolveConflict = useCallback(
+    async (mine: Snippet): Promise<Snippet | null> => {
+      const strategy = askConflictStrategy();
+      if (!strategy) return null;
+
+      const result = await app.ResolveSnippetConflict(mine, strategy);
+      if (result.saved) {
+        return result.snippet;
+      }
+      // 병합 충돌: 충돌 표시가 포함된 내용을 편집기에 불러와 직접 해결
+      setTitle(result.snippet.title);
+      setTags([...result.snippet.tags]);
+      setLanguage(result.snippet.language);
+      setIsFavorite(result.snippet.is_favorite);
+      setBody(result.snippet.body);
+      setRevision(result.snippet.revision);
+      alert(
+        "병합 충돌이 있습니다 (" +
+          result.conflicts.join(", ") +
+          "). 내용을 확인한 후 다시 저장하세요."
+      );
+      return null;
+    },
+    []
+  );
+
+  // 충돌 시 해결 절차를 거쳐 저장
+  const saveWithConflictHandling = useCallback(
+    async (mine: Snippet): Promise<Snippet | null> => {
+      try {
+        return await app.SaveSnippet(mine);
+      } catch (err) {
+        if (isConflictError(err)) {
+          return resolveConflict(mine);
+        }
+        throw err;
+      }
+    },
+    [resolveConflict]
+  );
+
   // tag/favorite 즉시 저장 헬퍼
   const saveTagsAndFavorite = useCallback(
     async (newTags: string[], newFavorite: boolean) => {
@@ -84,8 +151,11 @@ export function SnippetEditor({
           ...snippet,
           tags: newTags,
           is_favorite: newFavorite,
+          revision,
         };
-        await app.SaveSnippet(updatedSnippet);
+        const saved = await saveWithConflictHandling(updatedSnippet);
+        if (!saved) return;
+        setRevision(saved.revision);
         await app.ReloadSnippets();
         onListRefresh?.(); // 목록 갱신
       } catch (err) {
@@ -95,7 +165,7 @@ export function SnippetEditor({
         );
       }
     },
-    [snippet, onListRefresh]
+    [snippet, revision, saveWithConflictHandling, onListRefresh]
   );
 
   const handleAddTag = () => {
@@ -131,10 +201,12 @@ export function SnippetEditor({
         language,
         is_favorite: isFavorite,
         body,
+        revision,
       };
-      await app.SaveSnippet(updatedSnippet);
+      const saved = await saveWithConflictHandling(updatedSnippet);
+      if (!saved) return;
       await app.ReloadSnippets();
-      onSave(updatedSnippet);
+      onSave(saved);
     } catch (err) {
       alert(
         "Failed to save snippet: " +
@@ -153,7 +225,7 @@ export function SnippetEditor({
     try {
       await app.DeleteSnippet(snippet.id);
       await app.ReloadSnippets();
-      onDelete();
+      onDelete(snippet);
     } catch (err) {
       alert(
         "Failed to delete snippet: " +
@@ -162,9 +234,32 @@ export function SnippetEditor({
     }
   };
 
-  const handleCopyToClipboard = async () => {
+  const handleRevert = async (rev: string) => {
+    if (!snippet) return;
+
+    if (
+      isDirty &&
+      !confirm("저장하지 않은 변경사항이 있습니다. 이전 버전으로 되돌리시겠습니까?")
+    ) {
+      return;
+    }
+
     try {
-      await app.CopyToClipboard(body);
+      const reverted = await app.RevertSnippet(snippet.id, rev);
+      await app.ReloadSnippets();
+      setShowHistory(false);
+      onSave(reverted);
+    } catch (err) {
+      alert(
+        "Failed to revert snippet: " +
+          (err instanceof Error ? err.message : "Unknown error")
+      );
+    }
+  };
+
+  const copyText = async (text: string) => {
+    try {
+      await app.CopyToClipboard(text);
       alert("Copied to clipboard!");
     } catch (err) {
       alert(
@@ -174,6 +269,34 @@ export function SnippetEditor({
     }
   };
 
+  // 플레이스홀더가 있으면 값을 입력받은 후 복사
+  const handleCopyToClipboard = async () => {
+    // frontmatter에 params: false가 있으면 본문을 그대로 복사
+    if (snippet?.extra?.params === false) {
+      await copyText(body);
+      return;
+    }
+    const params = await app.GetSnippetParams(body);
+    if (params.length > 0) {
+      setCopyParams(params);
+      return;
+    }
+    // 이스케이프된 플레이스홀더(\<name>)의 백슬래시를 지우기 위해 렌더링
+    await copyText(await app.RenderSnippet(body, {}));
+  };
+
+  const handleParamsSubmit = async (values: Record<string, string>) => {
+    setCopyParams(null);
+    try {
+      await copyText(await app.RenderSnippet(body, values));
+    } catch (err) {
+      alert(
+        "Failed to fill parameters: " +
+          (err instanceof Error ? err.message : String(err))
+      );
+    }
+  };
+
   const handleToggleRawMode = () => {
     if (!rawMode) {
       // Enter raw mode - serialize current snippet
@@ -213,6 +336,20 @@ ${body}`;
 
   return (
     <div className="flex flex-col h-full">
+      {copyParams && (
+        <ParamsForm
+          params={copyParams}
+          onSubmit={handleParamsSubmit}
+          onCancel={() => setCopyParams(null)}
+        />
+      )}
+      {showHistory && (
+        <HistoryPanel
+          snippetId={snippet.id}
+          onRevert={handleRevert}
+          onClose={() => setShowHistory(false)}
+        />
+      )}
       {/* Header */}
       <div className="p-4 border-b border-gray-200 bg-white">
         <div className="flex items-center justify-between mb-4">
@@ -321,6 +458,12 @@ ${body}`;
           >
             Copy to Clipboard
           </button>
+          <button
+            onClick={() => setShowHistory(true)}
+            className="px-4 py-2 bg-gray-100 text-gray-600 rounded hover:bg-gray-200"
+          >
+            History
+          </button>
           <button
             onClick={handleDelete}
             className="px-4 py-2 bg-red-500 text-white rounded hover:bg-red-600"
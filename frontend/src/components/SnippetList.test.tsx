@@ -47,7 +47,7 @@ describe('SnippetList', () => {
     vi.clearAllMocks();
     const { app } = await import('../bridge');
     vi.mocked(app.GetAllSnippets).mockResolvedValue(mockSnippets);
-    vi.mocked(app.SearchSnippets).mockResolvedValue([mockSnippets[0]]);
+    vi.mocked(app.SearchSnippets).mockResolvedValue([{ snippet: mockSnippets[0], score: 1 }]);
   });
 
   describe('렌더링', () => {
@@ -154,6 +154,28 @@ describe('SnippetList', () => {
       });
     });
 
+    it('매치된 구간을 강조하고 본문 발췌를 표시한다', async () => {
+      const { app } = await import('../bridge');
+      vi.mocked(app.SearchSnippets).mockResolvedValueOnce([
+        {
+          snippet: mockSnippets[0],
+          score: 2.5,
+          matches: [
+            { field: 'title', index: 0, ranges: [{ start: 0, end: 5 }] },
+            { field: 'body', index: 0, ranges: [{ start: 6, end: 10 }] },
+          ],
+          excerpt: { text: 'first body', line: 1, ranges: [{ start: 6, end: 10 }] },
+        },
+      ]);
+
+      const { container } = render(<SnippetList {...defaultProps} searchQuery="first" />);
+
+      await waitFor(() => {
+        const marks = Array.from(container.querySelectorAll('mark')).map((m) => m.textContent);
+        expect(marks).toEqual(['First', 'body']);
+      });
+    });
+
     it('searchQuery가 비어있으면 GetAllSnippets를 호출한다', async () => {
       const { app } = await import('../bridge');
       render(<SnippetList {...defaultProps} searchQuery="" />);
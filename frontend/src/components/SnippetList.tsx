@@ -1,7 +1,35 @@
-import { useEffect, useState, useCallback } from "react";
-import { Snippet } from "../types";
+import { useEffect, useState, useCallback, ReactNode } from "react";
+import { Snippet, SearchResult, MatchRange } from "../types";
 import { app } from "../bridge";
 
+// 매치된 구간을 <mark>로 강조
+function Highlight({ text, ranges }: { text: string; ranges?: MatchRange[] }) {
+  if (!ranges || ranges.length === 0) {
+    return <>{text}</>;
+  }
+  const chars = Array.from(text); // range는 문자(코드 포인트) 단위
+  const parts: ReactNode[] = [];
+  let last = 0;
+  ranges.forEach((range, idx) => {
+    if (range.start < last) {
+      return;
+    }
+    if (range.start > last) {
+      parts.push(<span key={`t${idx}`}>{chars.slice(last, range.start).join("")}</span>);
+    }
+    parts.push(
+      <mark key={`m${idx}`} className="bg-yellow-200 rounded-sm">
+        {chars.slice(range.start, range.end).join("")}
+      </mark>
+    );
+    last = range.end;
+  });
+  if (last < chars.length) {
+    parts.push(<span key="rest">{chars.slice(last).join("")}</span>);
+  }
+  return <>{parts}</>;
+}
+
 interface SnippetListProps {
   onSelect: (snippet: Snippet) => void;
   searchQuery?: string;
@@ -10,7 +38,7 @@ interface SnippetListProps {
 }
 
 export function SnippetList({ onSelect, searchQuery = "", selectedId, refreshKey = 0 }: SnippetListProps) {
-  const [snippets, setSnippets] = useState<Snippet[]>([]);
+  const [results, setResults] = useState<SearchResult[]>([]);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState<string | null>(null);
 
@@ -18,13 +46,12 @@ export function SnippetList({ onSelect, searchQuery = "", selectedId, refreshKey
     try {
       setLoading(true);
       setError(null);
-      let result: Snippet[];
       if (searchQuery.trim()) {
-        result = await app.SearchSnippets(searchQuery);
+        setResults(await app.SearchSnippets(searchQuery));
       } else {
-        result = await app.GetAllSnippets();
+        const snippets = await app.GetAllSnippets();
+        setResults(snippets.map((snippet) => ({ snippet, score: 0 })));
       }
-      setSnippets(result);
     } catch (err) {
       setError(err instanceof Error ? err.message : "Failed to load snippets");
     } finally {
@@ -58,7 +85,7 @@ export function SnippetList({ onSelect, searchQuery = "", selectedId, refreshKey
     );
   }
 
-  if (snippets.length === 0) {
+  if (results.length === 0) {
     return (
       <div className="p-4">
         <p className="text-gray-500">No snippets found.</p>
@@ -68,41 +95,53 @@ export function SnippetList({ onSelect, searchQuery = "", selectedId, refreshKey
 
   return (
     <div className="divide-y divide-gray-200">
-      {snippets.map((snippet) => (
-        <div
-          key={snippet.id}
-          onClick={() => onSelect(snippet)}
-          className={`p-4 cursor-pointer transition-colors ${
-            selectedId === snippet.id
-              ? "bg-blue-50 border-l-4 border-blue-500"
-              : "hover:bg-gray-50"
-          }`}
-        >
-          <div className="flex items-center justify-between">
-            <div className="flex-1">
-              <h3 className="font-semibold text-lg">{snippet.title}</h3>
-              {snippet.tags.length > 0 && (
-                <div className="mt-1 flex flex-wrap gap-1">
-                  {snippet.tags.map((tag, idx) => (
-                    <span
-                      key={idx}
-                      className="px-2 py-1 text-xs bg-blue-100 text-blue-800 rounded"
-                    >
-                      {tag}
-                    </span>
-                  ))}
-                </div>
-              )}
-              {snippet.language && (
-                <span className="mt-1 inline-block text-xs text-gray-500">
-                  {snippet.language}
-                </span>
-              )}
+      {results.map(({ snippet, matches, excerpt }) => {
+        const titleRanges = matches?.find((m) => m.field === "title")?.ranges;
+        const tagRanges = (idx: number) =>
+          matches?.find((m) => m.field === "tags" && m.index === idx)?.ranges;
+        return (
+          <div
+            key={snippet.id}
+            onClick={() => onSelect(snippet)}
+            className={`p-4 cursor-pointer transition-colors ${
+              selectedId === snippet.id
+                ? "bg-blue-50 border-l-4 border-blue-500"
+                : "hover:bg-gray-50"
+            }`}
+          >
+            <div className="flex items-center justify-between">
+              <div className="flex-1">
+                <h3 className="font-semibold text-lg">
+                  <Highlight text={snippet.title} ranges={titleRanges} />
+                </h3>
+                {snippet.tags.length > 0 && (
+                  <div className="mt-1 flex flex-wrap gap-1">
+                    {snippet.tags.map((tag, idx) => (
+                      <span
+                        key={idx}
+                        className="px-2 py-1 text-xs bg-blue-100 text-blue-800 rounded"
+                      >
+                        <Highlight text={tag} ranges={tagRanges(idx)} />
+                      </span>
+                    ))}
+                  </div>
+                )}
+                {excerpt && (
+                  <p className="mt-1 text-xs text-gray-600 font-mono truncate" title={`line ${excerpt.line}`}>
+                    <Highlight text={excerpt.text} ranges={excerpt.ranges} />
+                  </p>
+                )}
+                {snippet.language && (
+                  <span className="mt-1 inline-block text-xs text-gray-500">
+                    {snippet.language}
+                  </span>
+                )}
+              </div>
+              {snippet.is_favorite && <span className="text-yellow-500">★</span>}
             </div>
-            {snippet.is_favorite && <span className="text-yellow-500">★</span>}
           </div>
-        </div>
-      ))}
+        );
+      })}
     </div>
   );
 }
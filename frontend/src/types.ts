@@ -7,6 +7,73 @@ export interface Snippet {
   created_at: string;
   updated_at: string;
   body: string;
+  revision?: string; // 파일 내용 해시 (동시 수정 감지용)
+  extra?: Record<string, unknown>; // snipgo가 모르는 frontmatter 키 (author, source 등)
 }
 
 
+
+// Emitted by the backend when a snippet file changes on disk
+export interface SnippetChangeEvent {
+  type: 'created' | 'updated' | 'deleted';
+  id: string;
+  snippet?: Snippet;
+}
+
+// Deleted snippet kept in the trash
+export interface TrashEntry {
+  id: string;
+  title: string;
+  path: string; // 삭제 전 파일 경로
+  deleted_at: string;
+}
+
+// A previous version of a snippet kept in its history
+export interface Version {
+  rev: string; // 버전 이름, 예: 20250102_150405.000000
+  number: number; // 가장 최근 버전이 1
+  saved_at: string; // 새 버전으로 교체된 시각
+  snippet: Snippet;
+}
+
+// Result of resolving a save conflict
+export interface ConflictResolution {
+  snippet: Snippet;
+  conflicts: string[];
+  saved: boolean;
+}
+
+export type ConflictStrategy = 'mine' | 'theirs' | 'merge';
+
+// Placeholder in a snippet body, e.g. <branch=main> or {{port:8080}}
+export interface SnippetParam {
+  name: string;
+  default: string;
+}
+
+// Half-open range [start, end) of character offsets
+export interface MatchRange {
+  start: number;
+  end: number;
+}
+
+// Where a search query matched in one field of a snippet
+export interface FieldMatch {
+  field: 'title' | 'tags' | 'body';
+  index: number; // tag index for 'tags'
+  ranges: MatchRange[];
+}
+
+// Short piece of the body around the first match
+export interface Excerpt {
+  text: string;
+  line: number;
+  ranges?: MatchRange[];
+}
+
+export interface SearchResult {
+  snippet: Snippet;
+  score: number;
+  matches?: FieldMatch[];
+  excerpt?: Excerpt;
+}
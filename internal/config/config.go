@@ -5,19 +5,69 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
+// Interactive pickers used by the CLI to select snippets
+const (
+	PickerBuiltin = "builtin" // bundled terminal UI
+	PickerFzf     = "fzf"     // external fzf binary
+)
+
+// DefaultHistoryLimit is the number of previous versions kept per snippet
+const DefaultHistoryLimit = 20
+
+// DefaultExecLog is the file exec records runs in
+const DefaultExecLog = "~/.local/state/snipgo/runs.jsonl"
+
+// ExecLogOff, as exec_log, turns off the run log
+const ExecLogOff = "off"
+
 // Config holds the application configuration
 type Config struct {
 	DataDirectory string `yaml:"data_directory"`
+	Picker        string `yaml:"picker,omitempty"`
+	// HistoryLimit is the number of previous versions kept per snippet;
+	// a negative value disables history
+	HistoryLimit int `yaml:"history_limit,omitempty"`
+	// Interpreters maps snippet languages to the command lines exec runs
+	// them with, e.g. sql: "psql -f {file}"
+	Interpreters map[string]string `yaml:"interpreters,omitempty"`
+	// ExecLog is the JSONL file runs of exec are recorded in, or ExecLogOff
+	ExecLog string `yaml:"exec_log,omitempty"`
 }
 
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	return &Config{
 		DataDirectory: "~/.config/snipgo/snippets",
+		Picker:        PickerBuiltin,
+		HistoryLimit:  DefaultHistoryLimit,
+		ExecLog:       DefaultExecLog,
+	}
+}
+
+// ExecLogPath returns the path of the run log, or "" if it is turned off
+func (c *Config) ExecLogPath() string {
+	switch c.ExecLog {
+	case ExecLogOff:
+		return ""
+	case "":
+		return expandPath(DefaultExecLog)
+	default:
+		return expandPath(c.ExecLog)
+	}
+}
+
+// ValidatePicker checks that name is a known picker
+func ValidatePicker(name string) error {
+	switch name {
+	case PickerBuiltin, PickerFzf:
+		return nil
+	default:
+		return fmt.Errorf("unknown picker %q (expected %s or %s)", name, PickerBuiltin, PickerFzf)
 	}
 }
 
@@ -52,6 +102,24 @@ func LoadConfig() (*Config, error) {
 	if fileConfig.DataDirectory != "" {
 		config.DataDirectory = expandPath(fileConfig.DataDirectory)
 	}
+	if fileConfig.Picker != "" {
+		if err := ValidatePicker(fileConfig.Picker); err != nil {
+			return config, err
+		}
+		config.Picker = fileConfig.Picker
+	}
+	if fileConfig.HistoryLimit != 0 {
+		config.HistoryLimit = fileConfig.HistoryLimit
+	}
+	if fileConfig.ExecLog != "" {
+		config.ExecLog = fileConfig.ExecLog
+	}
+	if len(fileConfig.Interpreters) > 0 {
+		config.Interpreters = make(map[string]string, len(fileConfig.Interpreters))
+		for language, command := range fileConfig.Interpreters {
+			config.Interpreters[strings.ToLower(language)] = command
+		}
+	}
 
 	return config, nil
 }
@@ -190,6 +190,120 @@ func TestLoadConfig(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_Picker(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	t.Setenv("SNIPGO_CONFIG_PATH", configPath)
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{name: "default", content: "data_directory: " + tmpDir + "\n", want: PickerBuiltin},
+		{name: "fzf", content: "picker: fzf\n", want: PickerFzf},
+		{name: "builtin", content: "picker: builtin\n", want: PickerBuiltin},
+		{name: "unknown", content: "picker: peco\n", want: PickerBuiltin, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(configPath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+
+			cfg, err := LoadConfig()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if cfg.Picker != tt.want {
+				t.Errorf("LoadConfig() Picker = %v, want %v", cfg.Picker, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_HistoryLimit(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	t.Setenv("SNIPGO_CONFIG_PATH", configPath)
+
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{name: "default", content: "data_directory: " + tmpDir + "\n", want: DefaultHistoryLimit},
+		{name: "custom", content: "history_limit: 5\n", want: 5},
+		{name: "disabled", content: "history_limit: -1\n", want: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(configPath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+
+			cfg, err := LoadConfig()
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			if cfg.HistoryLimit != tt.want {
+				t.Errorf("LoadConfig() HistoryLimit = %v, want %v", cfg.HistoryLimit, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_Interpreters(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	t.Setenv("SNIPGO_CONFIG_PATH", configPath)
+
+	content := "interpreters:\n  SQL: psql -f {file}\n  python: python3 -u\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if got := cfg.Interpreters["sql"]; got != "psql -f {file}" {
+		t.Errorf("LoadConfig() Interpreters[sql] = %q, want %q", got, "psql -f {file}")
+	}
+	if got := cfg.Interpreters["python"]; got != "python3 -u" {
+		t.Errorf("LoadConfig() Interpreters[python] = %q, want %q", got, "python3 -u")
+	}
+}
+
+func TestConfig_ExecLogPath(t *testing.T) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+
+	tests := []struct {
+		execLog string
+		want    string
+	}{
+		{execLog: "", want: filepath.Join(homeDir, ".local", "state", "snipgo", "runs.jsonl")},
+		{execLog: DefaultExecLog, want: filepath.Join(homeDir, ".local", "state", "snipgo", "runs.jsonl")},
+		{execLog: "/var/log/snipgo/runs.jsonl", want: "/var/log/snipgo/runs.jsonl"},
+		{execLog: ExecLogOff, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.execLog, func(t *testing.T) {
+			cfg := &Config{ExecLog: tt.execLog}
+			if got := cfg.ExecLogPath(); got != tt.want {
+				t.Errorf("ExecLogPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestExpandPath(t *testing.T) {
 	homeDir, _ := os.UserHomeDir()
 
@@ -0,0 +1,36 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// Committer records the changes the Manager makes to the data directory,
+// e.g. as commits in a git repository
+type Committer interface {
+	// Commit records all pending changes in the data directory with the
+	// given message. It does nothing if there are none.
+	Commit(message string) error
+}
+
+// SetCommitter makes the Manager record each change it makes to the data
+// directory with c. A nil Committer turns recording off.
+func (m *Manager) SetCommitter(c Committer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.committer = c
+}
+
+// commit records the changes of a mutation. Recording is best effort:
+// failures are logged and don't undo the mutation. The caller must hold the
+// storage lock.
+func (m *Manager) commit(format string, args ...any) {
+	if m.committer == nil {
+		return
+	}
+	message := fmt.Sprintf(format, args...)
+	if err := m.committer.Commit(message); err != nil {
+		slog.Warn("failed to commit change", "message", message, "error", err)
+	}
+}
@@ -0,0 +1,85 @@
+package core
+
+import "strings"
+
+// DiffOp is the kind of a line in a diff
+type DiffOp int
+
+const (
+	DiffEqual DiffOp = iota
+	DiffDelete
+	DiffInsert
+)
+
+// DiffLine is a single line of a line-based diff
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+// splitLines splits text into lines. An empty text has no lines.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// DiffLines computes a line-based diff turning a into b
+func DiffLines(a, b string) []DiffLine {
+	aLines, bLines := splitLines(a), splitLines(b)
+	pairs := matchLines(aLines, bLines)
+
+	var diff []DiffLine
+	i, j := 0, 0
+	for _, p := range append(pairs, [2]int{len(aLines), len(bLines)}) {
+		for ; i < p[0]; i++ {
+			diff = append(diff, DiffLine{Op: DiffDelete, Text: aLines[i]})
+		}
+		for ; j < p[1]; j++ {
+			diff = append(diff, DiffLine{Op: DiffInsert, Text: bLines[j]})
+		}
+		if i < len(aLines) && j < len(bLines) {
+			diff = append(diff, DiffLine{Op: DiffEqual, Text: aLines[i]})
+			i++
+			j++
+		}
+	}
+	return diff
+}
+
+// matchLines returns the index pairs of a longest common subsequence of a
+// and b, in increasing order. Snippets are small, so the quadratic dynamic
+// programming approach is fine.
+func matchLines(a, b []string) [][2]int {
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var pairs [][2]int
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			pairs = append(pairs, [2]int{i, j})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return pairs
+}
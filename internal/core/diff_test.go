@@ -0,0 +1,51 @@
+package core
+
+import "testing"
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want []DiffLine
+	}{
+		{
+			name: "identical",
+			a:    "a\nb",
+			b:    "a\nb",
+			want: []DiffLine{{DiffEqual, "a"}, {DiffEqual, "b"}},
+		},
+		{
+			name: "changed line",
+			a:    "a\nb\nc",
+			b:    "a\nx\nc",
+			want: []DiffLine{{DiffEqual, "a"}, {DiffDelete, "b"}, {DiffInsert, "x"}, {DiffEqual, "c"}},
+		},
+		{
+			name: "from empty",
+			a:    "",
+			b:    "a",
+			want: []DiffLine{{DiffInsert, "a"}},
+		},
+		{
+			name: "to empty",
+			a:    "a",
+			b:    "",
+			want: []DiffLine{{DiffDelete, "a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffLines(tt.a, tt.b)
+			if len(got) != len(tt.want) {
+				t.Fatalf("DiffLines() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("DiffLines()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"time"
+)
+
+// DefaultWatchDebounce is how long the watcher waits for a burst of file
+// events to settle before reloading. Editors often save through a temp
+// file and rename, producing several events for a single save.
+const DefaultWatchDebounce = 200 * time.Millisecond
+
+// ChangeType describes how a snippet changed on disk
+type ChangeType string
+
+const (
+	ChangeCreated ChangeType = "created"
+	ChangeUpdated ChangeType = "updated"
+	ChangeDeleted ChangeType = "deleted"
+)
+
+// ChangeEvent is emitted when the watcher applies an external change
+type ChangeEvent struct {
+	Type    ChangeType `json:"type"`
+	ID      string     `json:"id"`
+	Snippet *Snippet   `json:"snippet,omitempty"` // nil for deletions
+}
+
+// Watch watches the storage backend and incrementally updates the in-memory
+// snippets as files are created, modified, renamed or deleted. Events are
+// coalesced for the debounce duration, and handler is called once for every
+// snippet that actually changed. Changes made through this Manager are not
+// reported. Watch blocks until ctx is cancelled.
+func (m *Manager) Watch(ctx context.Context, debounce time.Duration, handler func(ChangeEvent)) error {
+	events, err := m.storage.Watch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to watch storage: %w", err)
+	}
+
+	if debounce <= 0 {
+		debounce = DefaultWatchDebounce
+	}
+
+	pending := make(map[string]struct{})
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-events:
+			if !ok {
+				return nil
+			}
+			slog.Debug("file event", "path", ev.Path, "op", ev.Op)
+			pending[ev.Path] = struct{}{}
+			timer.Reset(debounce)
+		case <-timer.C:
+			for _, path := range m.orderPending(pending) {
+				for _, change := range m.reloadPath(path) {
+					if handler != nil {
+						handler(change)
+					}
+				}
+			}
+			pending = make(map[string]struct{})
+		}
+	}
+}
+
+// orderPending returns the pending paths with existing files first, so that
+// a renamed file is picked up at its new path before the old path is
+// treated as a deletion
+func (m *Manager) orderPending(pending map[string]struct{}) []string {
+	var present, missing []string
+	for path := range pending {
+		if m.storage.FileExists(path) {
+			present = append(present, path)
+		} else {
+			missing = append(missing, path)
+		}
+	}
+	return append(present, missing...)
+}
+
+// reloadPath re-reads a single file and reconciles it with the in-memory
+// snippets, returning the resulting changes
+func (m *Manager) reloadPath(path string) []ChangeEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var changes []ChangeEvent
+
+	// The snippet previously stored in this file, if any
+	previousID := ""
+	for id, p := range m.paths {
+		if p == path {
+			previousID = id
+			break
+		}
+	}
+
+	var snippet *Snippet
+	if m.storage.FileExists(path) {
+		content, err := m.storage.ReadFile(path)
+		if err != nil {
+			slog.Warn("failed to read file", "path", path, "error", err)
+			return nil
+		}
+
+		snippet, err = ParseFrontmatter(content)
+		if err == nil {
+			err = snippet.Validate()
+		}
+		if err != nil {
+			// Possibly a partial write; a later event will pick it up
+			slog.Debug("ignoring unparsable file", "path", path, "error", err)
+			return nil
+		}
+		snippet.Revision = contentHash(content)
+	}
+
+	// The file was deleted or now holds a different snippet
+	if previousID != "" && (snippet == nil || snippet.ID != previousID) {
+		delete(m.snippets, previousID)
+		delete(m.paths, previousID)
+		m.index.remove(previousID)
+		changes = append(changes, ChangeEvent{Type: ChangeDeleted, ID: previousID})
+	}
+
+	if snippet == nil {
+		return changes
+	}
+
+	existing, known := m.snippets[snippet.ID]
+	if known {
+		oldPath := m.paths[snippet.ID]
+		if oldPath != path && m.storage.FileExists(oldPath) && !snippet.UpdatedAt.After(existing.UpdatedAt) {
+			// A stale copy of a snippet that lives elsewhere
+			slog.Warn("duplicate snippet ID, ignoring older file",
+				"id", snippet.ID, "path", path, "kept", oldPath)
+			return changes
+		}
+	}
+
+	m.snippets[snippet.ID] = snippet
+	m.paths[snippet.ID] = path
+	m.index.add(snippet)
+
+	switch {
+	case !known:
+		changes = append(changes, ChangeEvent{Type: ChangeCreated, ID: snippet.ID, Snippet: copySnippet(snippet)})
+	case !sameSnippet(existing, snippet):
+		changes = append(changes, ChangeEvent{Type: ChangeUpdated, ID: snippet.ID, Snippet: copySnippet(snippet)})
+	}
+
+	return changes
+}
+
+// sameSnippet reports whether two snippets have identical content
+func sameSnippet(a, b *Snippet) bool {
+	if a.ID != b.ID || a.Title != b.Title || a.Language != b.Language ||
+		a.IsFavorite != b.IsFavorite || a.Body != b.Body ||
+		!a.CreatedAt.Equal(b.CreatedAt) || !a.UpdatedAt.Equal(b.UpdatedAt) ||
+		len(a.Tags) != len(b.Tags) {
+		return false
+	}
+	for i := range a.Tags {
+		if a.Tags[i] != b.Tags[i] {
+			return false
+		}
+	}
+	return reflect.DeepEqual(a.Extra, b.Extra)
+}
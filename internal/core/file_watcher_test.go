@@ -0,0 +1,138 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"snipgo/internal/storage"
+)
+
+// startWatch runs Manager.Watch in the background and returns a channel of change events
+func startWatch(t *testing.T, m *Manager) <-chan ChangeEvent {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := make(chan ChangeEvent, 16)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := m.Watch(ctx, 20*time.Millisecond, func(ev ChangeEvent) { changes <- ev }); err != nil {
+			t.Errorf("Manager.Watch() error = %v", err)
+		}
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	// Give the backend watcher time to start
+	time.Sleep(50 * time.Millisecond)
+	return changes
+}
+
+// waitChange waits for the next change event
+func waitChange(t *testing.T, changes <-chan ChangeEvent) ChangeEvent {
+	t.Helper()
+	select {
+	case ev := <-changes:
+		return ev
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for change event")
+		return ChangeEvent{}
+	}
+}
+
+// expectNoChange fails if a change event arrives within a short period
+func expectNoChange(t *testing.T, changes <-chan ChangeEvent) {
+	t.Helper()
+	select {
+	case ev := <-changes:
+		t.Errorf("unexpected change event: %+v", ev)
+	case <-time.After(150 * time.Millisecond):
+	}
+}
+
+func TestManager_Watch_Memory(t *testing.T) {
+	backend := storage.NewMemory("/snippets")
+	m, err := NewManager(WithBackend(backend))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	changes := startWatch(t, m)
+
+	// External create
+	backend.WriteFile("/snippets/a.md", []byte("---\nid: id-a\ntitle: A\n---\nbody"))
+	ev := waitChange(t, changes)
+	if ev.Type != ChangeCreated || ev.ID != "id-a" || ev.Snippet == nil || ev.Snippet.Title != "A" {
+		t.Errorf("create event = %+v, want created id-a", ev)
+	}
+	if _, err := m.GetByID("id-a"); err != nil {
+		t.Errorf("Manager.GetByID() after create error = %v", err)
+	}
+
+	// External modification, written in several bursts
+	backend.WriteFile("/snippets/a.md", []byte("---\nid: id-a\ntitle: A\n---\npartial"))
+	backend.WriteFile("/snippets/a.md", []byte("---\nid: id-a\ntitle: A2\n---\nfull"))
+	ev = waitChange(t, changes)
+	if ev.Type != ChangeUpdated || ev.Snippet.Title != "A2" {
+		t.Errorf("update event = %+v, want updated title A2", ev)
+	}
+	expectNoChange(t, changes)
+
+	// External rename: new file appears, old one disappears
+	backend.WriteFile("/snippets/renamed.md", []byte("---\nid: id-a\ntitle: A2\n---\nfull"))
+	backend.DeleteFile("/snippets/a.md")
+	expectNoChange(t, changes)
+	m.mu.RLock()
+	got := m.paths["id-a"]
+	m.mu.RUnlock()
+	if got != "/snippets/renamed.md" {
+		t.Errorf("path after rename = %s, want /snippets/renamed.md", got)
+	}
+
+	// Saves made through the manager are not reported
+	snippet, _ := m.GetByID("id-a")
+	snippet.Body = "saved by manager"
+	if err := m.Save(snippet); err != nil {
+		t.Fatalf("Manager.Save() error = %v", err)
+	}
+	expectNoChange(t, changes)
+
+	// External delete
+	backend.DeleteFile("/snippets/renamed.md")
+	ev = waitChange(t, changes)
+	if ev.Type != ChangeDeleted || ev.ID != "id-a" {
+		t.Errorf("delete event = %+v, want deleted id-a", ev)
+	}
+	if _, err := m.GetByID("id-a"); err == nil {
+		t.Error("Manager.GetByID() after delete error = nil, want error")
+	}
+}
+
+func TestManager_Watch_FileSystem(t *testing.T) {
+	tmpDir := t.TempDir()
+	m, err := NewManager(WithDataDirectory(tmpDir))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	changes := startWatch(t, m)
+
+	// Editors commonly write a temp file and rename it into place
+	tmpPath := filepath.Join(tmpDir, ".snippet.md.swp")
+	content := []byte("---\nid: fs-id\ntitle: From Editor\n---\necho hi")
+	if err := os.WriteFile(tmpPath, content, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.Rename(tmpPath, filepath.Join(tmpDir, "snippet.md")); err != nil {
+		t.Fatalf("Failed to rename file: %v", err)
+	}
+
+	ev := waitChange(t, changes)
+	if ev.Type != ChangeCreated || ev.ID != "fs-id" {
+		t.Errorf("event = %+v, want created fs-id", ev)
+	}
+	expectNoChange(t, changes)
+}
@@ -2,6 +2,8 @@ package core
 
 import (
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -36,13 +38,27 @@ func ParseFrontmatter(content []byte) (*Snippet, error) {
 		return nil, fmt.Errorf("frontmatter delimiter not closed")
 	}
 
-	// Parse YAML frontmatter
+	// Parse YAML frontmatter. The node tree is kept so that unknown keys,
+	// key order and comments survive a later SerializeFrontmatter.
 	frontmatterText := strings.Join(frontmatterLines, "\n")
-	snippet := &Snippet{}
-	if err := yaml.Unmarshal([]byte(frontmatterText), snippet); err != nil {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal([]byte(frontmatterText), doc); err != nil {
 		return nil, fmt.Errorf("failed to parse frontmatter: %w", err)
 	}
 
+	snippet := &Snippet{}
+	if mapping := frontmatterMapping(doc); mapping != nil {
+		if err := mapping.Decode(snippet); err != nil {
+			return nil, fmt.Errorf("failed to parse frontmatter: %w", err)
+		}
+		extra, err := decodeExtra(mapping)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse frontmatter: %w", err)
+		}
+		snippet.Extra = extra
+		snippet.frontmatter = doc
+	}
+
 	// Extract body (everything after frontmatter)
 	bodyLines := lines[bodyStartIndex:]
 	body := strings.Join(bodyLines, "\n")
@@ -61,7 +77,11 @@ func SerializeFrontmatter(snippet *Snippet) ([]byte, error) {
 	}
 
 	// Marshal frontmatter to YAML
-	frontmatterBytes, err := yaml.Marshal(snippet)
+	doc, err := buildFrontmatter(snippet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal frontmatter: %w", err)
+	}
+	frontmatterBytes, err := yaml.Marshal(doc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal frontmatter: %w", err)
 	}
@@ -77,3 +97,174 @@ func SerializeFrontmatter(snippet *Snippet) ([]byte, error) {
 	result := strings.Join(parts, "\n")
 	return []byte(result), nil
 }
+
+// knownKeys lists the frontmatter keys backed by Snippet fields, in the
+// order they are written for new snippets
+var knownKeys = []string{"id", "title", "tags", "language", "is_favorite", "created_at", "updated_at"}
+
+// frontmatterMapping returns the top-level mapping of a parsed frontmatter
+// document, or nil if the frontmatter is empty
+func frontmatterMapping(doc *yaml.Node) *yaml.Node {
+	if doc == nil || doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+	return doc.Content[0]
+}
+
+// decodeExtra collects the frontmatter keys not backed by Snippet fields
+func decodeExtra(mapping *yaml.Node) (map[string]any, error) {
+	var extra map[string]any
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key := mapping.Content[i].Value
+		if isKnownKey(key) {
+			continue
+		}
+		var value any
+		if err := mapping.Content[i+1].Decode(&value); err != nil {
+			return nil, fmt.Errorf("key %s: %w", key, err)
+		}
+		if extra == nil {
+			extra = make(map[string]any)
+		}
+		extra[key] = value
+	}
+	return extra, nil
+}
+
+// isKnownKey reports whether key is backed by a Snippet field
+func isKnownKey(key string) bool {
+	for _, k := range knownKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// knownValues returns the values of the Snippet fields by frontmatter key
+func knownValues(snippet *Snippet) map[string]any {
+	tags := snippet.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return map[string]any{
+		"id":          snippet.ID,
+		"title":       snippet.Title,
+		"tags":        tags,
+		"language":    snippet.Language,
+		"is_favorite": snippet.IsFavorite,
+		"created_at":  snippet.CreatedAt,
+		"updated_at":  snippet.UpdatedAt,
+	}
+}
+
+// buildFrontmatter produces the YAML document for a snippet. If the snippet
+// was parsed from a file, that document is updated in place: existing keys
+// keep their order, formatting and comments unless their value changed,
+// extra keys are kept, and new keys are appended.
+func buildFrontmatter(snippet *Snippet) (*yaml.Node, error) {
+	doc := &yaml.Node{Kind: yaml.DocumentNode}
+	mapping := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if original := frontmatterMapping(snippet.frontmatter); original != nil {
+		doc = cloneNode(snippet.frontmatter)
+		mapping = doc.Content[0]
+	} else {
+		doc.Content = []*yaml.Node{mapping}
+	}
+
+	values := knownValues(snippet)
+	seen := make(map[string]bool)
+
+	// Update or drop existing keys
+	content := make([]*yaml.Node, 0, len(mapping.Content))
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		keyNode, valueNode := mapping.Content[i], mapping.Content[i+1]
+		key := keyNode.Value
+
+		value, ok := values[key]
+		if !ok {
+			value, ok = snippet.Extra[key]
+		}
+		if !ok || seen[key] {
+			continue // extra key removed by the caller, or duplicate
+		}
+		seen[key] = true
+
+		updated, err := updateValueNode(valueNode, value)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", key, err)
+		}
+		content = append(content, keyNode, updated)
+	}
+
+	// Append keys not present in the original document
+	appendKey := func(key string, value any) error {
+		if seen[key] {
+			return nil
+		}
+		valueNode := &yaml.Node{}
+		if err := valueNode.Encode(value); err != nil {
+			return fmt.Errorf("key %s: %w", key, err)
+		}
+		keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+		content = append(content, keyNode, valueNode)
+		return nil
+	}
+	for _, key := range knownKeys {
+		if err := appendKey(key, values[key]); err != nil {
+			return nil, err
+		}
+	}
+	extraKeys := make([]string, 0, len(snippet.Extra))
+	for key := range snippet.Extra {
+		if !isKnownKey(key) {
+			extraKeys = append(extraKeys, key)
+		}
+	}
+	sort.Strings(extraKeys)
+	for _, key := range extraKeys {
+		if err := appendKey(key, snippet.Extra[key]); err != nil {
+			return nil, err
+		}
+	}
+
+	mapping.Content = content
+	return doc, nil
+}
+
+// updateValueNode returns valueNode unchanged if it already holds value,
+// otherwise a new node for value carrying over valueNode's comments
+func updateValueNode(valueNode *yaml.Node, value any) (*yaml.Node, error) {
+	encoded := &yaml.Node{}
+	if err := encoded.Encode(value); err != nil {
+		return nil, err
+	}
+
+	var oldValue, newValue any
+	if valueNode.Decode(&oldValue) == nil && encoded.Decode(&newValue) == nil &&
+		reflect.DeepEqual(oldValue, newValue) {
+		return valueNode, nil
+	}
+
+	encoded.HeadComment = valueNode.HeadComment
+	encoded.LineComment = valueNode.LineComment
+	encoded.FootComment = valueNode.FootComment
+	return encoded, nil
+}
+
+// cloneNode returns a deep copy of a YAML node tree
+func cloneNode(n *yaml.Node) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+	c := *n
+	c.Content = make([]*yaml.Node, len(n.Content))
+	for i, child := range n.Content {
+		c.Content[i] = cloneNode(child)
+	}
+	c.Alias = cloneNode(n.Alias)
+	return &c
+}
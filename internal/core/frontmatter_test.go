@@ -339,3 +339,102 @@ func contains(s, substr string) bool {
 	return false
 }
 
+
+func TestFrontmatter_RoundTripPreservesUnknownKeys(t *testing.T) {
+	original := `---
+# Managed by hand, keep this comment
+title: Deploy # inline comment
+id: test-id
+author: jane
+tags: [ops, deploy]
+source:
+    url: https://example.com
+    line: 42
+language: sh
+is_favorite: false
+created_at: 2020-01-01T00:00:00Z
+updated_at: 2020-01-02T00:00:00Z
+ticket: OPS-123
+
+---
+
+kubectl rollout restart deploy/api`
+
+	snippet, err := ParseFrontmatter([]byte(original))
+	if err != nil {
+		t.Fatalf("ParseFrontmatter() error = %v", err)
+	}
+
+	if snippet.Extra["author"] != "jane" || snippet.Extra["ticket"] != "OPS-123" {
+		t.Errorf("ParseFrontmatter() Extra = %v, want author and ticket", snippet.Extra)
+	}
+	if source, ok := snippet.Extra["source"].(map[string]any); !ok || source["line"] != 42 {
+		t.Errorf("ParseFrontmatter() Extra[source] = %v, want nested map", snippet.Extra["source"])
+	}
+	for _, key := range knownKeys {
+		if _, ok := snippet.Extra[key]; ok {
+			t.Errorf("ParseFrontmatter() Extra contains known key %s", key)
+		}
+	}
+
+	t.Run("unchanged snippet is written back verbatim", func(t *testing.T) {
+		got, err := SerializeFrontmatter(snippet)
+		if err != nil {
+			t.Fatalf("SerializeFrontmatter() error = %v", err)
+		}
+		if string(got) != original {
+			t.Errorf("SerializeFrontmatter() =\n%s\nwant\n%s", got, original)
+		}
+	})
+
+	t.Run("edits keep order and comments", func(t *testing.T) {
+		edited := copySnippet(snippet)
+		edited.Title = "Deploy API"
+		edited.Tags = append(edited.Tags, "k8s")
+		delete(edited.Extra, "ticket")
+		edited.Extra["reviewed"] = true
+
+		got, err := SerializeFrontmatter(edited)
+		if err != nil {
+			t.Fatalf("SerializeFrontmatter() error = %v", err)
+		}
+		content := string(got)
+
+		for _, want := range []string{
+			"# Managed by hand, keep this comment\ntitle: Deploy API # inline comment\nid: test-id\nauthor: jane\n",
+			"source:\n    url: https://example.com\n    line: 42\n",
+			"reviewed: true\n",
+		} {
+			if !contains(content, want) {
+				t.Errorf("SerializeFrontmatter() = \n%s\nwant it to contain %q", content, want)
+			}
+		}
+		if contains(content, "ticket") {
+			t.Errorf("SerializeFrontmatter() kept removed key ticket:\n%s", content)
+		}
+
+		// The original snippet's document is not modified
+		again, _ := SerializeFrontmatter(snippet)
+		if string(again) != original {
+			t.Error("SerializeFrontmatter() mutated the original snippet's frontmatter")
+		}
+	})
+
+	t.Run("extra keys on new snippets", func(t *testing.T) {
+		fresh := &Snippet{ID: "new-id", Title: "New", Extra: map[string]any{"b": 1, "a": "x"}}
+		got, err := SerializeFrontmatter(fresh)
+		if err != nil {
+			t.Fatalf("SerializeFrontmatter() error = %v", err)
+		}
+		if !contains(string(got), "updated_at: 0001-01-01T00:00:00Z\na: x\nb: 1\n") {
+			t.Errorf("SerializeFrontmatter() = \n%s\nwant sorted extra keys after known keys", got)
+		}
+		parsed, err := ParseFrontmatter(got)
+		if err != nil {
+			t.Fatalf("ParseFrontmatter() error = %v", err)
+		}
+		if parsed.Extra["a"] != "x" || parsed.Extra["b"] != 1 {
+			t.Errorf("round-trip Extra = %v", parsed.Extra)
+		}
+	})
+}
@@ -0,0 +1,348 @@
+package core
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sahilm/fuzzy"
+)
+
+// excerptWidth is the maximum length of a body excerpt, in characters
+const excerptWidth = 80
+
+// MatchRange is a half-open range [Start, End) of character (rune) offsets
+type MatchRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// FieldMatch records where a query matched in one field of a snippet
+type FieldMatch struct {
+	Field  string       `json:"field"` // "title", "tags" or "body"
+	Index  int          `json:"index"` // index of the tag for "tags"
+	Ranges []MatchRange `json:"ranges"`
+}
+
+// Excerpt is a short piece of a snippet body, around the first match if
+// the body matched
+type Excerpt struct {
+	Text   string       `json:"text"`
+	Line   int          `json:"line"`             // 1-based body line the excerpt is taken from
+	Ranges []MatchRange `json:"ranges,omitempty"` // matches within Text
+}
+
+// highlightKind is how a highlight term is located in a field
+type highlightKind int
+
+const (
+	highlightPrefix    highlightKind = iota // word starting with the term
+	highlightSubstring                      // the term anywhere
+	highlightTag                            // the whole tag equal to the term
+)
+
+// highlightTerm is a positive part of a query that can be shown in results
+type highlightTerm struct {
+	kind  highlightKind
+	text  string // lowercase
+	field string // restricts the term to a field, "" for any
+}
+
+// highlighter computes matches and excerpts for a query
+type highlighter struct {
+	// groups holds the terms of each free-text term or phrase; a text term
+	// without any hit in a snippet is matched fuzzily against the title
+	groups []highlightGroup
+}
+
+type highlightGroup struct {
+	terms []highlightTerm
+	fuzzy string // free-text term for the fuzzy title fallback, if any
+}
+
+// newHighlighter collects the terms of a query worth highlighting. Negated
+// parts of the query are skipped.
+func newHighlighter(node queryNode) *highlighter {
+	h := &highlighter{}
+	h.collect(node)
+	return h
+}
+
+func (h *highlighter) collect(node queryNode) {
+	single := func(term highlightTerm) {
+		h.groups = append(h.groups, highlightGroup{terms: []highlightTerm{term}})
+	}
+
+	switch n := node.(type) {
+	case textNode:
+		group := highlightGroup{fuzzy: string(n)}
+		for _, token := range tokenize(string(n)) {
+			group.terms = append(group.terms, highlightTerm{kind: highlightPrefix, text: token})
+		}
+		h.groups = append(h.groups, group)
+	case phraseNode:
+		single(highlightTerm{kind: highlightSubstring, text: string(n)})
+	case fieldTextNode:
+		tokens := tokenize(n.text)
+		if len(tokens) == 0 {
+			single(highlightTerm{kind: highlightSubstring, text: n.text, field: n.field})
+			break
+		}
+		group := highlightGroup{}
+		for _, token := range tokens {
+			group.terms = append(group.terms, highlightTerm{kind: highlightPrefix, text: token, field: n.field})
+		}
+		h.groups = append(h.groups, group)
+	case tagNode:
+		single(highlightTerm{kind: highlightTag, text: string(n), field: "tags"})
+	case andNode:
+		for _, child := range n {
+			h.collect(child)
+		}
+	case orNode:
+		for _, child := range n {
+			h.collect(child)
+		}
+	}
+}
+
+// apply fills in the matches and excerpt of a search result
+func (h *highlighter) apply(r *SearchResult) {
+	s := r.Snippet
+	title := lowerForMatch(s.Title)
+	body := lowerForMatch(s.Body)
+	tags := make([]string, len(s.Tags))
+	for i, tag := range s.Tags {
+		tags[i] = lowerForMatch(tag)
+	}
+
+	// Byte ranges, converted to characters below
+	var titleRanges, bodyRanges []MatchRange
+	tagRanges := make([][]MatchRange, len(tags))
+
+	for _, group := range h.groups {
+		found := false
+		for _, term := range group.terms {
+			if term.field == "" || term.field == "title" {
+				ranges := findTerm(title, term)
+				titleRanges = append(titleRanges, ranges...)
+				found = found || len(ranges) > 0
+			}
+			if term.field == "" || term.field == "tags" {
+				for i, tag := range tags {
+					ranges := findTerm(tag, term)
+					tagRanges[i] = append(tagRanges[i], ranges...)
+					found = found || len(ranges) > 0
+				}
+			}
+			if term.field == "" || term.field == "body" {
+				ranges := findTerm(body, term)
+				bodyRanges = append(bodyRanges, ranges...)
+				found = found || len(ranges) > 0
+			}
+		}
+		if !found && group.fuzzy != "" {
+			titleRanges = append(titleRanges, fuzzyRanges(group.fuzzy, s.Title)...)
+		}
+	}
+
+	r.Matches = nil
+	if len(titleRanges) > 0 {
+		r.Matches = append(r.Matches, FieldMatch{Field: "title", Ranges: toRuneRanges(s.Title, mergeRanges(titleRanges))})
+	}
+	for i, ranges := range tagRanges {
+		if len(ranges) > 0 {
+			r.Matches = append(r.Matches, FieldMatch{Field: "tags", Index: i, Ranges: toRuneRanges(s.Tags[i], mergeRanges(ranges))})
+		}
+	}
+	bodyRanges = mergeRanges(bodyRanges)
+	if len(bodyRanges) > 0 {
+		r.Matches = append(r.Matches, FieldMatch{Field: "body", Ranges: toRuneRanges(s.Body, bodyRanges)})
+	}
+
+	r.Excerpt = makeExcerpt(s.Body, bodyRanges)
+}
+
+// lowerForMatch lowercases text keeping byte offsets intact, so that
+// positions found in the result are valid in text. Characters whose
+// lowercase form has a different length are left as is.
+func lowerForMatch(text string) string {
+	lower := strings.ToLower(text)
+	if len(lower) == len(text) {
+		return lower
+	}
+	return strings.Map(func(r rune) rune {
+		if l := unicode.ToLower(r); utf8.RuneLen(l) == utf8.RuneLen(r) {
+			return l
+		}
+		return r
+	}, text)
+}
+
+// findTerm returns the byte ranges of the lowercase text matching a
+// highlight term
+func findTerm(text string, term highlightTerm) []MatchRange {
+	if term.text == "" {
+		return nil
+	}
+
+	var ranges []MatchRange
+	switch term.kind {
+	case highlightTag:
+		if text == term.text {
+			ranges = append(ranges, MatchRange{0, len(text)})
+		}
+	case highlightSubstring, highlightPrefix:
+		for offset := 0; ; {
+			i := strings.Index(text[offset:], term.text)
+			if i < 0 {
+				break
+			}
+			start := offset + i
+			offset = start + 1
+			if term.kind == highlightPrefix && start > 0 {
+				// Only at the start of a word
+				if r, _ := utf8.DecodeLastRuneInString(text[:start]); unicode.IsLetter(r) || unicode.IsDigit(r) {
+					continue
+				}
+			}
+			ranges = append(ranges, MatchRange{start, start + len(term.text)})
+		}
+	}
+	return ranges
+}
+
+// toRuneRanges converts sorted byte ranges in text to character ranges
+func toRuneRanges(text string, ranges []MatchRange) []MatchRange {
+	if isASCII(text) {
+		return ranges
+	}
+	out := make([]MatchRange, len(ranges))
+	for i, r := range ranges {
+		out[i] = MatchRange{
+			Start: utf8.RuneCountInString(text[:r.Start]),
+			End:   utf8.RuneCountInString(text[:r.End]),
+		}
+	}
+	return out
+}
+
+func isASCII(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if text[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// fuzzyRanges returns the byte ranges of the characters of title matched
+// fuzzily by term
+func fuzzyRanges(term, title string) []MatchRange {
+	matches := fuzzy.Find(term, []string{title})
+	if len(matches) == 0 {
+		return nil
+	}
+
+	var ranges []MatchRange
+	for _, i := range matches[0].MatchedIndexes {
+		_, size := utf8.DecodeRuneInString(title[i:])
+		ranges = append(ranges, MatchRange{i, i + size})
+	}
+	return ranges
+}
+
+// mergeRanges sorts ranges and merges overlapping and adjacent ones
+func mergeRanges(ranges []MatchRange) []MatchRange {
+	if len(ranges) == 0 {
+		return nil
+	}
+	slices.SortFunc(ranges, func(a, b MatchRange) int { return a.Start - b.Start })
+
+	merged := []MatchRange{ranges[0]}
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r.Start <= last.End {
+			last.End = max(last.End, r.End)
+		} else {
+			merged = append(merged, r)
+		}
+	}
+	return merged
+}
+
+// makeExcerpt returns the body line holding the first match, shortened to
+// about excerptWidth characters around it, or the first non-blank line if
+// the body did not match. ranges are sorted byte ranges in body.
+func makeExcerpt(body string, ranges []MatchRange) *Excerpt {
+	// Find the line to show
+	var lineStart int
+	if len(ranges) > 0 {
+		lineStart = strings.LastIndexByte(body[:ranges[0].Start], '\n') + 1
+	} else {
+		trimmed := strings.TrimLeftFunc(body, unicode.IsSpace)
+		if trimmed == "" {
+			return nil
+		}
+		first := len(body) - len(trimmed)
+		lineStart = strings.LastIndexByte(body[:first], '\n') + 1
+	}
+	lineEnd := len(body)
+	if i := strings.IndexByte(body[lineStart:], '\n'); i >= 0 {
+		lineEnd = lineStart + i
+	}
+	lineNo := strings.Count(body[:lineStart], "\n") + 1
+
+	// Drop indentation
+	line := strings.TrimLeftFunc(body[lineStart:lineEnd], unicode.IsSpace)
+	lineStart = lineEnd - len(line)
+
+	// Window of the line around the first match, in bytes
+	start, end := lineStart, lineEnd
+	if utf8.RuneCountInString(line) > excerptWidth {
+		if len(ranges) > 0 {
+			// Keep a little context before the match
+			start = ranges[0].Start
+			for n := 0; n < excerptWidth/4 && start > lineStart; n++ {
+				_, size := utf8.DecodeLastRuneInString(body[lineStart:start])
+				start -= size
+			}
+		}
+		end = start
+		for n := 0; n < excerptWidth && end < lineEnd; n++ {
+			_, size := utf8.DecodeRuneInString(body[end:lineEnd])
+			end += size
+		}
+		// Use the whole width near the end of the line
+		for n := utf8.RuneCountInString(body[start:end]); n < excerptWidth && start > lineStart; n++ {
+			_, size := utf8.DecodeLastRuneInString(body[lineStart:start])
+			start -= size
+		}
+	}
+
+	prefix, suffix := "", ""
+	if start > lineStart {
+		prefix = "…"
+	}
+	if end < lineEnd {
+		suffix = "…"
+	}
+	text := body[start:end]
+
+	excerpt := &Excerpt{Text: prefix + text + suffix, Line: lineNo}
+	offset := utf8.RuneCountInString(prefix)
+	for _, r := range ranges {
+		if r.Start >= end {
+			break
+		}
+		if r.End <= start {
+			continue
+		}
+		excerpt.Ranges = append(excerpt.Ranges, MatchRange{
+			Start: offset + utf8.RuneCountInString(body[start:max(r.Start, start)]),
+			End:   offset + utf8.RuneCountInString(body[start:min(r.End, end)]),
+		})
+	}
+	return excerpt
+}
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"snipgo/internal/storage"
+)
+
+func TestManager_Search_Matches(t *testing.T) {
+	m, err := NewManager(WithBackend(storage.NewMemory("/snippets")))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	snippets := []*Snippet{
+		{ID: "01A", Title: "Docker logs", Tags: []string{"docker", "ops"},
+			Body: "# follow logs\ndocker logs -f app"},
+		{ID: "01B", Title: "Größe prüfen", Body: "du -sh größe"},
+		{ID: "01C", Title: "JavaScript Basics", Body: "console.log('hi')"},
+	}
+	for _, s := range snippets {
+		m.snippets[s.ID] = s
+		m.index.add(s)
+	}
+
+	tests := []struct {
+		query       string
+		id          string
+		wantMatches []FieldMatch
+		wantExcerpt *Excerpt
+	}{
+		{
+			query: "dock",
+			id:    "01A",
+			wantMatches: []FieldMatch{
+				{Field: "title", Ranges: []MatchRange{{0, 4}}},
+				{Field: "tags", Index: 0, Ranges: []MatchRange{{0, 4}}},
+				{Field: "body", Ranges: []MatchRange{{14, 18}}},
+			},
+			wantExcerpt: &Excerpt{Text: "docker logs -f app", Line: 2, Ranges: []MatchRange{{0, 4}}},
+		},
+		{
+			query: `"logs -f" tag:ops -missing`,
+			id:    "01A",
+			wantMatches: []FieldMatch{
+				{Field: "tags", Index: 1, Ranges: []MatchRange{{0, 3}}},
+				{Field: "body", Ranges: []MatchRange{{21, 28}}},
+			},
+			wantExcerpt: &Excerpt{Text: "docker logs -f app", Line: 2, Ranges: []MatchRange{{7, 14}}},
+		},
+		{
+			// Ranges count characters, not bytes
+			query: "größe",
+			id:    "01B",
+			wantMatches: []FieldMatch{
+				{Field: "title", Ranges: []MatchRange{{0, 5}}},
+				{Field: "body", Ranges: []MatchRange{{7, 12}}},
+			},
+			wantExcerpt: &Excerpt{Text: "du -sh größe", Line: 1, Ranges: []MatchRange{{7, 12}}},
+		},
+		{
+			// Fuzzy title matches report the matched characters
+			query: "jscript",
+			id:    "01C",
+			wantMatches: []FieldMatch{
+				{Field: "title", Ranges: []MatchRange{{0, 1}, {4, 10}}},
+			},
+			wantExcerpt: &Excerpt{Text: "console.log('hi')", Line: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			results, err := m.SearchQuery(tt.query)
+			if err != nil {
+				t.Fatalf("SearchQuery(%q) error = %v", tt.query, err)
+			}
+			var result *SearchResult
+			for _, r := range results {
+				if r.Snippet.ID == tt.id {
+					result = r
+				}
+			}
+			if result == nil {
+				t.Fatalf("SearchQuery(%q) did not return %s", tt.query, tt.id)
+			}
+			if !reflect.DeepEqual(result.Matches, tt.wantMatches) {
+				t.Errorf("Matches = %+v, want %+v", result.Matches, tt.wantMatches)
+			}
+			if !reflect.DeepEqual(result.Excerpt, tt.wantExcerpt) {
+				t.Errorf("Excerpt = %+v, want %+v", result.Excerpt, tt.wantExcerpt)
+			}
+		})
+	}
+}
+
+func TestMakeExcerpt_LongLine(t *testing.T) {
+	body := strings.Repeat("a ", 60) + "needle" + strings.Repeat(" b", 60)
+	start := strings.Index(body, "needle")
+
+	excerpt := makeExcerpt(body, []MatchRange{{start, start + len("needle")}})
+	if excerpt == nil {
+		t.Fatal("makeExcerpt() = nil")
+	}
+	if n := len([]rune(excerpt.Text)); n > excerptWidth+2 {
+		t.Errorf("excerpt is %d characters, want at most %d", n, excerptWidth+2)
+	}
+	if !strings.HasPrefix(excerpt.Text, "…") || !strings.HasSuffix(excerpt.Text, "…") {
+		t.Errorf("excerpt %q should be elided on both sides", excerpt.Text)
+	}
+	if len(excerpt.Ranges) != 1 {
+		t.Fatalf("excerpt ranges = %v, want 1", excerpt.Ranges)
+	}
+	r := excerpt.Ranges[0]
+	if got := string([]rune(excerpt.Text)[r.Start:r.End]); got != "needle" {
+		t.Errorf("excerpt range covers %q, want %q", got, "needle")
+	}
+}
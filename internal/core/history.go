@@ -0,0 +1,182 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HistoryDirName is the directory in the data directory that keeps previous
+// versions of snippets, in one subdirectory per snippet ID
+const HistoryDirName = ".history"
+
+// historyTimestampFormat names the files in a snippet's history; names sort
+// in the order the versions were replaced
+const historyTimestampFormat = "20060102_150405.000000"
+
+// Version is a previous version of a snippet kept in its history
+type Version struct {
+	Rev     string    `json:"rev"`      // name of the version, e.g. 20250102_150405.000000
+	Number  int       `json:"number"`   // 1 for the most recent version, 2 for the one before, ...
+	SavedAt time.Time `json:"saved_at"` // when the version was replaced by a newer one
+	Snippet *Snippet  `json:"snippet"`
+}
+
+// historyDir returns the directory holding the history of a snippet
+func (m *Manager) historyDir(id string) string {
+	return filepath.Join(m.storage.GetSnippetsDir(), HistoryDirName, id)
+}
+
+// recordVersion keeps the content of the file at path, about to be replaced
+// by newContent, in the history of the snippet and drops the versions
+// beyond the history limit. History is best effort: failures are logged and
+// don't prevent the save. The caller must hold the storage lock.
+func (m *Manager) recordVersion(id, path string, newContent []byte) {
+	if m.historyLimit <= 0 || path == "" {
+		return
+	}
+
+	old, err := m.storage.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("failed to read previous version", "id", id, "path", path, "error", err)
+		}
+		return
+	}
+	if bytes.Equal(old, newContent) {
+		return
+	}
+
+	dir := m.historyDir(id)
+	if err := m.storage.MakeDir(dir); err != nil {
+		slog.Warn("failed to create history directory", "id", id, "error", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	file := filepath.Join(dir, now.Format(historyTimestampFormat)+".md")
+	for m.storage.FileExists(file) {
+		now = now.Add(time.Microsecond)
+		file = filepath.Join(dir, now.Format(historyTimestampFormat)+".md")
+	}
+	if err := m.storage.WriteFile(file, old); err != nil {
+		slog.Warn("failed to record previous version", "id", id, "error", err)
+		return
+	}
+
+	files, err := m.historyFiles(id)
+	if err != nil {
+		slog.Warn("failed to list history", "id", id, "error", err)
+		return
+	}
+	for i := m.historyLimit; i < len(files); i++ {
+		if err := m.storage.DeleteFile(files[i]); err != nil {
+			slog.Warn("failed to remove old version", "path", files[i], "error", err)
+		}
+	}
+}
+
+// historyFiles returns the files in the history of a snippet, most recent
+// first
+func (m *Manager) historyFiles(id string) ([]string, error) {
+	files, err := m.storage.ListDir(m.historyDir(id))
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, file := range files {
+		if _, ok := parseRev(file); ok {
+			versions = append(versions, file)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(versions)))
+	return versions, nil
+}
+
+// parseRev returns the time a history file was recorded, from its name
+func parseRev(file string) (time.Time, bool) {
+	name := filepath.Base(file)
+	if !strings.HasSuffix(name, ".md") {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(historyTimestampFormat, strings.TrimSuffix(name, ".md"))
+	return t, err == nil
+}
+
+// History returns the previous versions of a snippet, most recent first
+func (m *Manager) History(id string) ([]*Version, error) {
+	files, err := m.historyFiles(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list history: %w", err)
+	}
+
+	versions := make([]*Version, 0, len(files))
+	for i, file := range files {
+		content, err := m.storage.ReadFile(file)
+		if err != nil {
+			slog.Warn("failed to read version", "path", file, "error", err)
+			continue
+		}
+		snippet, err := ParseFrontmatter(content)
+		if err != nil {
+			slog.Warn("failed to parse version", "path", file, "error", err)
+			continue
+		}
+		snippet.Revision = contentHash(content)
+
+		savedAt, _ := parseRev(file)
+		versions = append(versions, &Version{
+			Rev:     strings.TrimSuffix(filepath.Base(file), ".md"),
+			Number:  i + 1,
+			SavedAt: savedAt,
+			Snippet: snippet,
+		})
+	}
+	return versions, nil
+}
+
+// GetVersion returns a previous version of a snippet, given its Rev or its
+// Number
+func (m *Manager) GetVersion(id, rev string) (*Version, error) {
+	versions, err := m.History(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range versions {
+		if v.Rev == rev || strconv.Itoa(v.Number) == rev {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("version %s of snippet %s not found", rev, id)
+}
+
+// Revert saves a previous version of a snippet as its current version. The
+// version being replaced goes to the history like on any save, so a revert
+// can itself be reverted.
+func (m *Manager) Revert(id, rev string) (*Snippet, error) {
+	current, err := m.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	version, err := m.GetVersion(id, rev)
+	if err != nil {
+		return nil, err
+	}
+
+	reverted := copySnippet(version.Snippet)
+	reverted.ID = current.ID
+	reverted.CreatedAt = current.CreatedAt
+	reverted.Revision = current.Revision
+	if err := m.Save(reverted); err != nil {
+		return nil, err
+	}
+	return reverted, nil
+}
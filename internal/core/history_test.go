@@ -0,0 +1,122 @@
+package core
+
+import (
+	"testing"
+
+	"snipgo/internal/storage"
+)
+
+func TestManager_History(t *testing.T) {
+	m, err := NewManager(WithBackend(storage.NewMemory("/snippets")), WithHistoryLimit(3))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	snippet := NewSnippet("Greeting")
+	for _, body := range []string{"v1", "v2", "v3", "v4", "v5"} {
+		snippet.Body = body
+		if err := m.Save(snippet); err != nil {
+			t.Fatalf("Save(%s) error = %v", body, err)
+		}
+	}
+
+	versions, err := m.History(snippet.ID)
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+
+	// v5 is current; v4, v3 and v2 fit in the limit, v1 was dropped
+	var bodies []string
+	for _, v := range versions {
+		bodies = append(bodies, v.Snippet.Body)
+	}
+	want := []string{"v4", "v3", "v2"}
+	if len(bodies) != len(want) {
+		t.Fatalf("History() bodies = %q, want %q", bodies, want)
+	}
+	for i := range want {
+		if bodies[i] != want[i] {
+			t.Errorf("History()[%d] body = %q, want %q", i, bodies[i], want[i])
+		}
+		if versions[i].Number != i+1 {
+			t.Errorf("History()[%d].Number = %d, want %d", i, versions[i].Number, i+1)
+		}
+	}
+	if !versions[0].SavedAt.After(versions[1].SavedAt) {
+		t.Errorf("History() not most recent first: %v, %v", versions[0].SavedAt, versions[1].SavedAt)
+	}
+
+	if v, err := m.GetVersion(snippet.ID, versions[1].Rev); err != nil || v.Number != 2 {
+		t.Errorf("GetVersion(rev) = %v, %v, want version 2", v, err)
+	}
+	if v, err := m.GetVersion(snippet.ID, "3"); err != nil || v.Rev != versions[2].Rev {
+		t.Errorf("GetVersion(3) = %v, %v, want version 3", v, err)
+	}
+	if _, err := m.GetVersion(snippet.ID, "9"); err == nil {
+		t.Error("GetVersion(9) error = nil, want error")
+	}
+}
+
+func TestManager_Revert(t *testing.T) {
+	m, err := NewManager(WithBackend(storage.NewMemory("/snippets")))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	snippet := NewSnippet("Greeting")
+	snippet.Body = "hello"
+	snippet.Tags = []string{"old"}
+	if err := m.Save(snippet); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	snippet.Body = "goodbye"
+	snippet.Tags = []string{"new"}
+	if err := m.Save(snippet); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	reverted, err := m.Revert(snippet.ID, "1")
+	if err != nil {
+		t.Fatalf("Revert() error = %v", err)
+	}
+	if reverted.Body != "hello" || len(reverted.Tags) != 1 || reverted.Tags[0] != "old" {
+		t.Errorf("Revert() = body %q tags %v, want hello [old]", reverted.Body, reverted.Tags)
+	}
+	if reverted.CreatedAt.Unix() != snippet.CreatedAt.Unix() {
+		t.Errorf("Revert() CreatedAt = %v, want %v", reverted.CreatedAt, snippet.CreatedAt)
+	}
+
+	current, _ := m.GetByID(snippet.ID)
+	if current.Body != "hello" {
+		t.Errorf("GetByID() after Revert body = %q, want %q", current.Body, "hello")
+	}
+
+	// The reverted-from version is in the history
+	versions, _ := m.History(snippet.ID)
+	if len(versions) != 2 || versions[0].Snippet.Body != "goodbye" {
+		t.Errorf("History() after Revert = %d versions, want goodbye first", len(versions))
+	}
+}
+
+func TestManager_History_Disabled(t *testing.T) {
+	m, err := NewManager(WithBackend(storage.NewMemory("/snippets")), WithHistoryLimit(0))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	snippet := NewSnippet("Greeting")
+	for _, body := range []string{"v1", "v2"} {
+		snippet.Body = body
+		if err := m.Save(snippet); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	versions, err := m.History(snippet.ID)
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(versions) != 0 {
+		t.Errorf("History() = %d versions, want none", len(versions))
+	}
+}
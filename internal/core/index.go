@@ -0,0 +1,264 @@
+package core
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Index fields, in the order of their term frequencies in a posting
+const (
+	fieldTitle = iota
+	fieldTags
+	fieldBody
+	numFields
+)
+
+// fieldBoosts weight a term occurrence by the field it appears in
+var fieldBoosts = [numFields]float64{
+	fieldTitle: 3.0,
+	fieldTags:  2.0,
+	fieldBody:  1.0,
+}
+
+// BM25 parameters: k1 controls term frequency saturation, b how strongly
+// scores are normalized by field length
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// prefixWeight discounts terms that only match a query token as a prefix,
+// so "kube" finds "kubectl" but ranks an exact "kube" higher
+const prefixWeight = 0.8
+
+// posting holds the frequency of a term in each field of a snippet
+type posting struct {
+	doc   int32 // document number
+	freqs [numFields]int32
+}
+
+// indexedDoc is what the index keeps about a snippet
+type indexedDoc struct {
+	id      string // snippet ID, empty for a free slot
+	title   string
+	lengths [numFields]int // number of tokens per field
+	terms   []string       // distinct terms, for removal
+}
+
+// searchIndex is an in-memory inverted index over snippet titles, tags and
+// bodies, scored with BM25F. Snippets are numbered internally so postings
+// and score accumulators can be plain slices. It is not safe for concurrent
+// mutation; the Manager guards it with its own lock.
+type searchIndex struct {
+	postings map[string][]posting // term -> postings, in no particular order
+	docs     []indexedDoc         // by document number
+	numbers  map[string]int32     // snippet ID -> document number
+	free     []int32              // document numbers of removed snippets
+	vocab    []string             // sorted terms, for prefix lookups
+	totals   [numFields]int       // total tokens per field
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		postings: make(map[string][]posting),
+		numbers:  make(map[string]int32),
+	}
+}
+
+// tokenize splits text into lowercase tokens of letters and digits
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// rebuild replaces the contents of the index
+func (idx *searchIndex) rebuild(snippets map[string]*Snippet) {
+	*idx = *newSearchIndex()
+	idx.docs = make([]indexedDoc, 0, len(snippets))
+	for _, s := range snippets {
+		idx.insert(s)
+	}
+	idx.vocab = make([]string, 0, len(idx.postings))
+	for term := range idx.postings {
+		idx.vocab = append(idx.vocab, term)
+	}
+	sort.Strings(idx.vocab)
+}
+
+// add indexes a snippet, replacing its previous version
+func (idx *searchIndex) add(s *Snippet) {
+	idx.remove(s.ID)
+	for _, term := range idx.insert(s) {
+		if i, found := slices.BinarySearch(idx.vocab, term); !found {
+			idx.vocab = slices.Insert(idx.vocab, i, term)
+		}
+	}
+}
+
+// insert indexes a snippet without updating the vocabulary and returns the
+// terms that are new to the index
+func (idx *searchIndex) insert(s *Snippet) []string {
+	fields := [numFields][]string{
+		fieldTitle: tokenize(s.Title),
+		fieldTags:  tokenize(strings.Join(s.Tags, " ")),
+		fieldBody:  tokenize(s.Body),
+	}
+
+	var number int32
+	if n := len(idx.free); n > 0 {
+		number, idx.free = idx.free[n-1], idx.free[:n-1]
+	} else {
+		number = int32(len(idx.docs))
+		idx.docs = append(idx.docs, indexedDoc{})
+	}
+
+	doc := indexedDoc{id: s.ID, title: s.Title}
+	freqs := make(map[string]*posting)
+	for field, tokens := range fields {
+		doc.lengths[field] = len(tokens)
+		idx.totals[field] += len(tokens)
+		for _, token := range tokens {
+			p, ok := freqs[token]
+			if !ok {
+				p = &posting{doc: number}
+				freqs[token] = p
+				doc.terms = append(doc.terms, token)
+			}
+			p.freqs[field]++
+		}
+	}
+
+	var added []string
+	for _, term := range doc.terms {
+		postings, ok := idx.postings[term]
+		if !ok {
+			added = append(added, term)
+		}
+		idx.postings[term] = append(postings, *freqs[term])
+	}
+	idx.docs[number] = doc
+	idx.numbers[s.ID] = number
+	return added
+}
+
+// remove drops a snippet from the index
+func (idx *searchIndex) remove(id string) {
+	number, ok := idx.numbers[id]
+	if !ok {
+		return
+	}
+	doc := idx.docs[number]
+	for field, n := range doc.lengths {
+		idx.totals[field] -= n
+	}
+	for _, term := range doc.terms {
+		postings := slices.DeleteFunc(idx.postings[term], func(p posting) bool {
+			return p.doc == number
+		})
+		if len(postings) > 0 {
+			idx.postings[term] = postings
+			continue
+		}
+		delete(idx.postings, term)
+		if i, found := slices.BinarySearch(idx.vocab, term); found {
+			idx.vocab = slices.Delete(idx.vocab, i, i+1)
+		}
+	}
+	idx.docs[number] = indexedDoc{}
+	idx.free = append(idx.free, number)
+	delete(idx.numbers, id)
+}
+
+// expand returns the indexed terms matching a query token, exactly or as a
+// prefix, with their weights
+func (idx *searchIndex) expand(token string) map[string]float64 {
+	terms := make(map[string]float64)
+	i, _ := slices.BinarySearch(idx.vocab, token)
+	for ; i < len(idx.vocab) && strings.HasPrefix(idx.vocab[i], token); i++ {
+		if idx.vocab[i] == token {
+			terms[idx.vocab[i]] = 1
+		} else {
+			terms[idx.vocab[i]] = prefixWeight
+		}
+	}
+	return terms
+}
+
+// score returns the BM25F scores of the snippets containing every token of
+// text, keyed by snippet ID. Each token matches indexed terms exactly or as
+// a prefix; a snippet's score for a token is that of its best matching term.
+func (idx *searchIndex) score(text string) map[string]float64 {
+	tokens := tokenize(text)
+	n := len(idx.numbers)
+	if len(tokens) == 0 || n == 0 {
+		return nil
+	}
+
+	var avgLen [numFields]float64
+	for field, total := range idx.totals {
+		avgLen[field] = max(float64(total)/float64(n), 1)
+	}
+
+	total := make([]float64, len(idx.docs))
+	matched := make([]int, len(idx.docs)) // number of tokens matched
+	best := make([]float64, len(idx.docs))
+	for i, token := range tokens {
+		var touched []int32
+		for term, weight := range idx.expand(token) {
+			postings := idx.postings[term]
+			df := float64(len(postings))
+			idf := math.Log(1 + (float64(n)-df+0.5)/(df+0.5))
+
+			for _, p := range postings {
+				// Only snippets matching every previous token can match
+				if matched[p.doc] != i {
+					continue
+				}
+				lengths := &idx.docs[p.doc].lengths
+				tf := 0.0
+				for field, freq := range p.freqs {
+					if freq == 0 {
+						continue
+					}
+					norm := 1 - bm25B + bm25B*float64(lengths[field])/avgLen[field]
+					tf += fieldBoosts[field] * float64(freq) / norm
+				}
+				s := weight * idf * tf * (bm25K1 + 1) / (tf + bm25K1)
+				if best[p.doc] == 0 {
+					touched = append(touched, p.doc)
+				}
+				best[p.doc] = max(best[p.doc], s)
+			}
+		}
+		for _, doc := range touched {
+			total[doc] += best[doc]
+			matched[doc]++
+			best[doc] = 0
+		}
+	}
+
+	scores := make(map[string]float64)
+	for doc, count := range matched {
+		if count == len(tokens) {
+			scores[idx.docs[doc].id] = total[doc]
+		}
+	}
+	return scores
+}
+
+// titles returns the IDs and titles of all indexed snippets
+func (idx *searchIndex) titles() ([]string, []string) {
+	ids := make([]string, 0, len(idx.numbers))
+	titles := make([]string, 0, len(idx.numbers))
+	for _, doc := range idx.docs {
+		if doc.id != "" {
+			ids = append(ids, doc.id)
+			titles = append(titles, doc.title)
+		}
+	}
+	return ids, titles
+}
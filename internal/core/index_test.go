@@ -0,0 +1,252 @@
+package core
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"snipgo/internal/storage"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"Hello, World!", []string{"hello", "world"}},
+		{"docker-compose up -d", []string{"docker", "compose", "up", "d"}},
+		{"k8s get_pods --all", []string{"k8s", "get", "pods", "all"}},
+		{"Größe ändern", []string{"größe", "ändern"}},
+	}
+
+	for _, tt := range tests {
+		if got := tokenize(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestSearchIndex_AddRemove(t *testing.T) {
+	idx := newSearchIndex()
+	idx.add(&Snippet{ID: "a", Title: "Docker logs", Tags: []string{"docker"}, Body: "docker logs -f app"})
+	idx.add(&Snippet{ID: "b", Title: "Kubectl logs", Body: "kubectl logs pod"})
+
+	if !sort.StringsAreSorted(idx.vocab) {
+		t.Errorf("vocab = %q, want sorted", idx.vocab)
+	}
+	if got := idx.score("logs"); len(got) != 2 {
+		t.Errorf("score(logs) matched %d snippets, want 2", len(got))
+	}
+
+	// Re-adding replaces the previous version
+	idx.add(&Snippet{ID: "a", Title: "Docker ps", Body: "docker ps -a"})
+	if got := idx.score("logs"); len(got) != 1 {
+		t.Errorf("score(logs) after update matched %d snippets, want 1", len(got))
+	}
+	if slices.Contains(idx.vocab, "f") {
+		t.Error("vocab still contains a term only the old version had")
+	}
+
+	idx.remove("a")
+	idx.remove("b")
+	if len(idx.postings) != 0 || len(idx.vocab) != 0 || len(idx.numbers) != 0 {
+		t.Errorf("index not empty after removing everything: %d postings, %d terms, %d docs",
+			len(idx.postings), len(idx.vocab), len(idx.numbers))
+	}
+	if idx.totals != [numFields]int{} {
+		t.Errorf("totals = %v, want zero", idx.totals)
+	}
+
+	// Document numbers of removed snippets are reused
+	idx.add(&Snippet{ID: "c", Title: "Git status", Body: "git status"})
+	if len(idx.docs) != 2 {
+		t.Errorf("len(docs) = %d after reusing a free slot, want 2", len(idx.docs))
+	}
+	if got := idx.score("git"); len(got) != 1 || got["c"] == 0 {
+		t.Errorf("score(git) = %v, want only c", got)
+	}
+}
+
+func TestSearchIndex_Score(t *testing.T) {
+	idx := newSearchIndex()
+	idx.add(&Snippet{ID: "short", Title: "Restart nginx", Body: "systemctl restart nginx"})
+	idx.add(&Snippet{ID: "long", Title: "Server maintenance",
+		Body: "systemctl restart nginx\nsystemctl restart postgres\njournalctl -u nginx --since today"})
+	idx.add(&Snippet{ID: "other", Title: "List files", Body: "ls -la"})
+
+	t.Run("shorter field ranks higher", func(t *testing.T) {
+		scores := idx.score("restart")
+		if scores["short"] <= scores["long"] {
+			t.Errorf("score(short) = %v, want > score(long) = %v", scores["short"], scores["long"])
+		}
+	})
+
+	t.Run("every token must match", func(t *testing.T) {
+		scores := idx.score("restart postgres")
+		if _, ok := scores["long"]; !ok || len(scores) != 1 {
+			t.Errorf("score(restart postgres) = %v, want only long", scores)
+		}
+	})
+
+	t.Run("prefix matches rank below exact matches", func(t *testing.T) {
+		idx.add(&Snippet{ID: "exact", Title: "Journal", Body: "journal"})
+		scores := idx.score("journal")
+		if _, ok := scores["long"]; !ok {
+			t.Fatalf("score(journal) = %v, want a prefix match on journalctl", scores)
+		}
+		if scores["exact"] <= scores["long"] {
+			t.Errorf("score(exact) = %v, want > prefix score %v", scores["exact"], scores["long"])
+		}
+		idx.remove("exact")
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		if scores := idx.score("docker"); len(scores) != 0 {
+			t.Errorf("score(docker) = %v, want none", scores)
+		}
+	})
+}
+
+func TestManager_SearchIndexMaintained(t *testing.T) {
+	backend := storage.NewMemory("/snippets")
+	m, err := NewManager(WithBackend(backend))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	ids := func(query string) []string {
+		var got []string
+		for _, r := range m.Search(query) {
+			got = append(got, r.Snippet.ID)
+		}
+		return got
+	}
+
+	s := &Snippet{ID: "01A", Title: "Tail logs", Body: "tail -f /var/log/syslog"}
+	if err := m.Save(s); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if got := ids("syslog"); !slices.Equal(got, []string{"01A"}) {
+		t.Errorf("Search(syslog) after Save = %v, want [01A]", got)
+	}
+
+	s.Body = "journalctl -f"
+	if err := m.Save(s); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if got := ids("syslog"); len(got) != 0 {
+		t.Errorf("Search(syslog) after update = %v, want none", got)
+	}
+
+	// LoadAll rebuilds the index from storage
+	if err := m.LoadAll(); err != nil {
+		t.Fatalf("LoadAll() error = %v", err)
+	}
+	if got := ids("journalctl"); !slices.Equal(got, []string{"01A"}) {
+		t.Errorf("Search(journalctl) after LoadAll = %v, want [01A]", got)
+	}
+
+	if err := m.Delete("01A"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got := ids("journalctl"); len(got) != 0 {
+		t.Errorf("Search(journalctl) after Delete = %v, want none", got)
+	}
+}
+
+// benchWords is the vocabulary of generated benchmark snippets
+var benchWords = strings.Fields(`docker kubectl git ssh curl grep awk sed find xargs tar gzip
+	nginx postgres redis kafka deploy restart logs tail status build test lint push pull
+	commit rebase merge branch checkout config secret volume network port proxy cert
+	backup restore migrate schema index query select insert update delete user group
+	chmod chown mount disk memory cpu process signal kill service systemctl journal`)
+
+// newBenchManager returns a Manager holding n generated snippets
+func newBenchManager(b *testing.B, n int) *Manager {
+	b.Helper()
+
+	m, err := NewManager(WithBackend(storage.NewMemory("/snippets")))
+	if err != nil {
+		b.Fatalf("NewManager() error = %v", err)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	rare := make([]string, 20000)
+	for i := range rare {
+		word := make([]byte, 4+rng.Intn(6))
+		for j := range word {
+			word[j] = byte('a' + rng.Intn(26))
+		}
+		rare[i] = string(word)
+	}
+	words := func(k int) string {
+		out := make([]string, k)
+		for i := range out {
+			// Mix common words with a long tail of rarer ones
+			if rng.Intn(10) < 3 {
+				out[i] = benchWords[rng.Intn(len(benchWords))]
+			} else {
+				out[i] = rare[rng.Intn(len(rare))]
+			}
+		}
+		return strings.Join(out, " ")
+	}
+
+	for i := 0; i < n; i++ {
+		s := &Snippet{
+			ID:       fmt.Sprintf("%08d", i),
+			Title:    words(3 + rng.Intn(4)),
+			Tags:     strings.Fields(words(1 + rng.Intn(3))),
+			Language: "sh",
+			Body:     words(10 + rng.Intn(40)),
+		}
+		m.snippets[s.ID] = s
+	}
+	m.index.rebuild(m.snippets)
+	return m
+}
+
+// BenchmarkSearch_50k measures query latency over 50k generated snippets
+func BenchmarkSearch_50k(b *testing.B) {
+	m := newBenchManager(b, 50000)
+
+	queries := []string{
+		"docker",
+		"docker logs",
+		"kube",
+		"restart nginx",
+		`"git push"`,
+		"tag:docker -kafka",
+		"nginx412",
+	}
+	for _, query := range queries {
+		b.Run(query, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				m.Search(query)
+			}
+		})
+	}
+}
+
+func BenchmarkSearchIndex_Add(b *testing.B) {
+	m := newBenchManager(b, 50000)
+	s := &Snippet{ID: "bench", Title: "Restart nginx", Tags: []string{"nginx"}, Body: "systemctl restart nginx"}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.index.add(s)
+	}
+}
+
+func BenchmarkSearchIndex_Rebuild(b *testing.B) {
+	m := newBenchManager(b, 50000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.index.rebuild(m.snippets)
+	}
+}
@@ -1,37 +1,124 @@
 package core
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log/slog"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
+	"snipgo/internal/config"
 	"snipgo/internal/storage"
 )
 
+// filenameTimestampFormat is the timestamp layout used in generated filenames
+const filenameTimestampFormat = "20060102_150405"
+
 // Manager manages snippets in memory and on disk
 type Manager struct {
 	snippets map[string]*Snippet // key: snippet ID
-	storage  *storage.FileSystem
+	paths    map[string]string   // key: snippet ID, value: backing file path
+	index    *searchIndex        // full-text index over snippets
+	storage  storage.Backend
 	mu       sync.RWMutex
+
+	historyLimit int       // previous versions kept per snippet, 0 to keep none
+	committer    Committer // records changes, nil if not set
 }
 
-// NewManager creates a new Manager instance
-func NewManager() (*Manager, error) {
-	fs, err := storage.NewFileSystem()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create filesystem: %w", err)
+// Option configures a Manager
+type Option func(*managerOptions) error
+
+type managerOptions struct {
+	backend      storage.Backend
+	historyLimit *int
+}
+
+// WithBackend makes the Manager use the given storage backend
+// instead of the configured data directory
+func WithBackend(backend storage.Backend) Option {
+	return func(o *managerOptions) error {
+		if backend == nil {
+			return fmt.Errorf("backend cannot be nil")
+		}
+		o.backend = backend
+		return nil
+	}
+}
+
+// WithDataDirectory makes the Manager store snippets on disk in dir
+// instead of the configured data directory
+func WithDataDirectory(dir string) Option {
+	return func(o *managerOptions) error {
+		fs, err := storage.NewFileSystemAt(dir)
+		if err != nil {
+			return fmt.Errorf("failed to create filesystem: %w", err)
+		}
+		o.backend = fs
+		return nil
+	}
+}
+
+// WithHistoryLimit sets how many previous versions of each snippet are kept
+// in its history. Zero or a negative limit disables history.
+func WithHistoryLimit(limit int) Option {
+	return func(o *managerOptions) error {
+		limit = max(limit, 0)
+		o.historyLimit = &limit
+		return nil
+	}
+}
+
+// NewManager creates a new Manager instance. Without options, snippets are
+// stored in the data directory from the config file, which also sets the
+// history limit.
+func NewManager(opts ...Option) (*Manager, error) {
+	options := &managerOptions{}
+	for _, opt := range opts {
+		if err := opt(options); err != nil {
+			return nil, err
+		}
+	}
+
+	historyLimit := config.DefaultHistoryLimit
+	if options.backend == nil {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+		fs, err := storage.NewFileSystemAt(cfg.DataDirectory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create filesystem: %w", err)
+		}
+		options.backend = fs
+		historyLimit = max(cfg.HistoryLimit, 0)
+	}
+	if options.historyLimit != nil {
+		historyLimit = *options.historyLimit
 	}
 
 	m := &Manager{
-		snippets: make(map[string]*Snippet),
-		storage:  fs,
+		snippets:     make(map[string]*Snippet),
+		paths:        make(map[string]string),
+		index:        newSearchIndex(),
+		storage:      options.backend,
+		historyLimit: historyLimit,
 	}
 
 	return m, nil
 }
 
+// Storage returns the backend the Manager reads from and writes to
+func (m *Manager) Storage() storage.Backend {
+	return m.storage
+}
+
 // LoadAll loads all snippets from disk into memory
 func (m *Manager) LoadAll() error {
 	m.mu.Lock()
@@ -44,6 +131,7 @@ func (m *Manager) LoadAll() error {
 
 	// Clear existing snippets
 	m.snippets = make(map[string]*Snippet)
+	m.paths = make(map[string]string)
 
 	// Load each file
 	for _, filepath := range files {
@@ -65,14 +153,32 @@ func (m *Manager) LoadAll() error {
 			slog.Warn("invalid snippet in file", "path", filepath, "error", err)
 			continue
 		}
+		snippet.Revision = contentHash(content)
+
+		// Several files may carry the same ID (e.g. stale copies left by older
+		// versions). Keep the most recently updated one.
+		if existing, ok := m.snippets[snippet.ID]; ok {
+			if !snippet.UpdatedAt.After(existing.UpdatedAt) {
+				slog.Warn("duplicate snippet ID, ignoring older file",
+					"id", snippet.ID, "path", filepath, "kept", m.paths[snippet.ID])
+				continue
+			}
+			slog.Warn("duplicate snippet ID, ignoring older file",
+				"id", snippet.ID, "path", m.paths[snippet.ID], "kept", filepath)
+		}
 
 		m.snippets[snippet.ID] = snippet
+		m.paths[snippet.ID] = filepath
 	}
 
+	m.index.rebuild(m.snippets)
+
 	return nil
 }
 
-// Save saves a snippet to disk
+// Save saves a snippet to disk. If the snippet's file was changed on disk
+// since it was read (see Snippet.Revision), nothing is written and an
+// ErrConflict is returned.
 func (m *Manager) Save(snippet *Snippet) error {
 	if err := snippet.Validate(); err != nil {
 		return fmt.Errorf("invalid snippet: %w", err)
@@ -81,6 +187,25 @@ func (m *Manager) Save(snippet *Snippet) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	// Serialize with other processes writing to the same store
+	unlock, err := m.storage.Lock()
+	if err != nil {
+		return fmt.Errorf("failed to lock storage: %w", err)
+	}
+	defer unlock()
+
+	oldPath := m.pathForID(snippet.ID)
+	if err := m.checkConflict(snippet, oldPath); err != nil {
+		return err
+	}
+	previous := m.snippets[snippet.ID]
+
+	// Snippets that went through JSON (e.g. the GUI) lost their parsed
+	// frontmatter; reuse the loaded one to keep comments and key order
+	if snippet.frontmatter == nil && previous != nil {
+		snippet.frontmatter = previous.frontmatter
+	}
+
 	// Update timestamp
 	snippet.UpdateTimestamp()
 
@@ -90,57 +215,133 @@ func (m *Manager) Save(snippet *Snippet) error {
 		return fmt.Errorf("failed to serialize snippet: %w", err)
 	}
 
-	// Generate filename: {Title}_{Timestamp}.md
-	filename := generateFilename(snippet)
-	filepath := filepath.Join(m.storage.GetSnippetsDir(), filename)
+	newPath := m.targetPath(snippet, oldPath)
+
+	// Keep the version being replaced
+	m.recordVersion(snippet.ID, oldPath, content)
 
 	// Write to disk
-	if err := m.storage.WriteFile(filepath, content); err != nil {
+	if err := m.storage.WriteFile(newPath, content); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
+	// Remove the previous file once the new one is safely written
+	if oldPath != "" && oldPath != newPath {
+		if err := m.storage.DeleteFile(oldPath); err != nil {
+			slog.Warn("failed to remove previous snippet file", "path", oldPath, "error", err)
+		}
+	}
+
 	// Update in-memory index
-	m.snippets[snippet.ID] = snippet
+	snippet.Revision = contentHash(content)
+	m.snippets[snippet.ID] = copySnippet(snippet)
+	m.index.add(snippet)
+	m.paths[snippet.ID] = newPath
+
+	switch {
+	case previous == nil:
+		m.commit("Add snippet '%s'", snippet.Title)
+	case previous.Title != snippet.Title:
+		m.commit("Rename snippet '%s' to '%s'", previous.Title, snippet.Title)
+	default:
+		m.commit("Update snippet '%s'", snippet.Title)
+	}
 
 	return nil
 }
 
-// Delete deletes a snippet from disk and memory
+// checkConflict returns an ErrConflict if the file at path no longer holds
+// the revision the snippet was based on
+func (m *Manager) checkConflict(snippet *Snippet, path string) error {
+	if path == "" {
+		return nil
+	}
+
+	expected := snippet.Revision
+	previous := m.snippets[snippet.ID]
+	if expected == "" && previous != nil {
+		expected = previous.Revision
+	}
+	if expected == "" {
+		return nil
+	}
+
+	content, err := m.storage.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		// Deleted externally; saving recreates it
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	actual := contentHash(content)
+	if actual == expected {
+		return nil
+	}
+
+	conflict := ErrConflict{ID: snippet.ID, Path: path}
+	if theirs, err := ParseFrontmatter(content); err == nil {
+		theirs.Revision = actual
+		conflict.Theirs = theirs
+	}
+	if previous != nil && previous.Revision == expected {
+		conflict.Base = copySnippet(previous)
+	}
+	return conflict
+}
+
+// targetPath returns the file path a snippet should be written to.
+// A snippet keeps its current file unless its title changed and the file
+// name was generated by snipgo; files named by the user are never renamed.
+func (m *Manager) targetPath(snippet *Snippet, oldPath string) string {
+	if oldPath != "" {
+		previous := m.snippets[snippet.ID]
+		if previous == nil || previous.Title == snippet.Title ||
+			!isGeneratedFilename(filepath.Base(oldPath), previous.Title) {
+			return oldPath
+		}
+	}
+
+	dir := m.storage.GetSnippetsDir()
+	path := filepath.Join(dir, generateFilename(snippet))
+	if m.storage.FileExists(path) {
+		// Another snippet with the same title was saved within the same second
+		base := strings.TrimSuffix(filepath.Base(path), ".md")
+		path = filepath.Join(dir, fmt.Sprintf("%s_%s.md", base, snippet.ID))
+	}
+	return path
+}
+
+// Delete removes a snippet from memory and moves its file to the trash,
+// from which Restore can bring it back
 func (m *Manager) Delete(id string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if _, exists := m.snippets[id]; !exists {
+	snippet, exists := m.snippets[id]
+	if !exists {
 		return fmt.Errorf("snippet with ID %s not found", id)
 	}
 
-	// Find and delete the file
-	files, err := m.storage.ListFiles()
+	unlock, err := m.storage.Lock()
 	if err != nil {
-		return fmt.Errorf("failed to list files: %w", err)
+		return fmt.Errorf("failed to lock storage: %w", err)
 	}
+	defer unlock()
 
-	for _, filepath := range files {
-		content, err := m.storage.ReadFile(filepath)
-		if err != nil {
-			continue
-		}
-
-		fileSnippet, err := ParseFrontmatter(content)
-		if err != nil {
-			continue
-		}
-
-		if fileSnippet.ID == id {
-			if err := m.storage.DeleteFile(filepath); err != nil {
-				return fmt.Errorf("failed to delete file: %w", err)
-			}
-			break
+	if path := m.pathForID(id); path != "" {
+		if err := m.moveToTrash(id, path); err != nil {
+			return err
 		}
 	}
 
 	// Remove from memory
 	delete(m.snippets, id)
+	delete(m.paths, id)
+	m.index.remove(id)
+
+	m.commit("Delete snippet '%s'", snippet.Title)
 
 	return nil
 }
@@ -172,10 +373,94 @@ func (m *Manager) GetAll() []*Snippet {
 	return snippets
 }
 
+// Tags returns the distinct tags of all snippets, sorted
+func (m *Manager) Tags() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var tags []string
+	for _, snippet := range m.snippets {
+		for _, tag := range snippet.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// pathForID returns the file backing the snippet with the given ID, or ""
+// if no file holds it. An unknown ID is searched for in the store, as its
+// file may have been created by another program since LoadAll; files
+// already backing other snippets are skipped.
+func (m *Manager) pathForID(id string) string {
+	if path, ok := m.paths[id]; ok {
+		return path
+	}
+
+	files, err := m.storage.ListFiles()
+	if err != nil {
+		return ""
+	}
+
+	known := make(map[string]bool, len(m.paths))
+	for _, path := range m.paths {
+		known[path] = true
+	}
+
+	for _, path := range files {
+		if known[path] {
+			continue
+		}
+		content, err := m.storage.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		fileSnippet, err := ParseFrontmatter(content)
+		if err != nil {
+			continue
+		}
+
+		if fileSnippet.ID == id {
+			return path
+		}
+	}
+
+	return ""
+}
+
 // generateFilename generates a filename for a snippet
 func generateFilename(snippet *Snippet) string {
-	// Sanitize title for filename
-	title := strings.ReplaceAll(snippet.Title, " ", "_")
+	title := sanitizeTitle(snippet.Title)
+
+	// Use timestamp for uniqueness
+	timestamp := snippet.UpdatedAt.Format(filenameTimestampFormat)
+
+	return fmt.Sprintf("%s_%s.md", title, timestamp)
+}
+
+// isGeneratedFilename reports whether name looks like a filename produced by
+// generateFilename for a snippet with the given title
+func isGeneratedFilename(name, title string) bool {
+	prefix := sanitizeTitle(title) + "_"
+	if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".md") {
+		return false
+	}
+	rest := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".md")
+	if len(rest) < len(filenameTimestampFormat) {
+		return false
+	}
+	_, err := time.Parse(filenameTimestampFormat, rest[:len(filenameTimestampFormat)])
+	return err == nil
+}
+
+// sanitizeTitle replaces characters that are unsafe in filenames
+func sanitizeTitle(title string) string {
+	title = strings.ReplaceAll(title, " ", "_")
 	title = strings.ReplaceAll(title, "/", "_")
 	title = strings.ReplaceAll(title, "\\", "_")
 	title = strings.ReplaceAll(title, ":", "_")
@@ -185,11 +470,7 @@ func generateFilename(snippet *Snippet) string {
 	title = strings.ReplaceAll(title, "<", "_")
 	title = strings.ReplaceAll(title, ">", "_")
 	title = strings.ReplaceAll(title, "|", "_")
-
-	// Use timestamp for uniqueness
-	timestamp := snippet.UpdatedAt.Format("20060102_150405")
-
-	return fmt.Sprintf("%s_%s.md", title, timestamp)
+	return title
 }
 
 // copySnippet creates a deep copy of a snippet
@@ -206,5 +487,27 @@ func copySnippet(s *Snippet) *Snippet {
 		CreatedAt:  s.CreatedAt,
 		UpdatedAt:  s.UpdatedAt,
 		Body:       s.Body,
+		Revision:   s.Revision,
+		Extra:      copyExtra(s.Extra),
+
+		frontmatter: s.frontmatter, // never mutated, see buildFrontmatter
+	}
+}
+
+// copyExtra copies the top level of an Extra map
+func copyExtra(extra map[string]any) map[string]any {
+	if extra == nil {
+		return nil
+	}
+	c := make(map[string]any, len(extra))
+	for k, v := range extra {
+		c[k] = v
 	}
+	return c
+}
+
+// contentHash returns the revision identifier for file content
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
 }
@@ -3,8 +3,11 @@ package core
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"snipgo/internal/storage"
 )
 
 // setupTestConfig creates a temporary config file and sets SNIPGO_CONFIG_PATH
@@ -559,6 +562,29 @@ func TestManager_GetAll(t *testing.T) {
 	}
 }
 
+func TestManager_Tags(t *testing.T) {
+	m, err := NewManager(WithBackend(storage.NewMemory("/snippets")))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	for _, s := range []*Snippet{
+		{ID: "test-id-1", Title: "Snippet 1", Tags: []string{"k8s", "docker"}},
+		{ID: "test-id-2", Title: "Snippet 2", Tags: []string{"docker", "aws"}},
+		{ID: "test-id-3", Title: "Snippet 3"},
+	} {
+		if err := m.Save(s); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	got := m.Tags()
+	want := []string{"aws", "docker", "k8s"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Manager.Tags() = %v, want %v", got, want)
+	}
+}
+
 func TestGenerateFilename(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -698,3 +724,335 @@ func TestCopySnippet(t *testing.T) {
 }
 
 
+
+// snippetFiles returns all files on disk that hold the given snippet ID
+func snippetFiles(t *testing.T, m *Manager, id string) []string {
+	t.Helper()
+
+	files, err := m.storage.ListFiles()
+	if err != nil {
+		t.Fatalf("Failed to list files: %v", err)
+	}
+
+	var matches []string
+	for _, file := range files {
+		content, err := m.storage.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		fileSnippet, err := ParseFrontmatter(content)
+		if err != nil {
+			continue
+		}
+		if fileSnippet.ID == id {
+			matches = append(matches, file)
+		}
+	}
+	return matches
+}
+
+func TestManager_Save_ReplacesFile(t *testing.T) {
+	tests := []struct {
+		name         string
+		existingFile string // file created before LoadAll, "" to create via Save
+		newTitle     string
+		wantRenamed  bool
+	}{
+		{
+			name:        "resave keeps a single file",
+			newTitle:    "Original Title",
+			wantRenamed: false,
+		},
+		{
+			name:        "title change renames generated file",
+			newTitle:    "Renamed Title",
+			wantRenamed: true,
+		},
+		{
+			name:         "title change keeps user-named file",
+			existingFile: "my-notes.md",
+			newTitle:     "Renamed Title",
+			wantRenamed:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			cleanup, err := setupTestConfig(tmpDir)
+			if err != nil {
+				t.Fatalf("Failed to setup test config: %v", err)
+			}
+			defer cleanup()
+
+			m, err := NewManager()
+			if err != nil {
+				t.Fatalf("Failed to create manager: %v", err)
+			}
+
+			if tt.existingFile != "" {
+				content := "---\nid: test-id\ntitle: Original Title\n---\nBody"
+				if err := os.WriteFile(filepath.Join(tmpDir, tt.existingFile), []byte(content), 0644); err != nil {
+					t.Fatalf("Failed to write file: %v", err)
+				}
+			} else {
+				if err := m.Save(&Snippet{ID: "test-id", Title: "Original Title", Body: "Body"}); err != nil {
+					t.Fatalf("Failed to save snippet: %v", err)
+				}
+			}
+			if err := m.LoadAll(); err != nil {
+				t.Fatalf("Failed to load snippets: %v", err)
+			}
+
+			before := snippetFiles(t, m, "test-id")
+			if len(before) != 1 {
+				t.Fatalf("expected 1 file before save, got %d", len(before))
+			}
+
+			snippet, err := m.GetByID("test-id")
+			if err != nil {
+				t.Fatalf("Failed to get snippet: %v", err)
+			}
+			snippet.Title = tt.newTitle
+			snippet.Body = "Updated body"
+			if err := m.Save(snippet); err != nil {
+				t.Fatalf("Manager.Save() error = %v", err)
+			}
+
+			after := snippetFiles(t, m, "test-id")
+			if len(after) != 1 {
+				t.Fatalf("Manager.Save() left %d files for snippet, want 1: %v", len(after), after)
+			}
+
+			renamed := after[0] != before[0]
+			if renamed != tt.wantRenamed {
+				t.Errorf("Manager.Save() renamed = %v, want %v (before %s, after %s)", renamed, tt.wantRenamed, before[0], after[0])
+			}
+
+			if tt.wantRenamed && !isGeneratedFilename(filepath.Base(after[0]), tt.newTitle) {
+				t.Errorf("Manager.Save() file %s is not named after new title %q", after[0], tt.newTitle)
+			}
+		})
+	}
+}
+
+func TestManager_Save_UnloadedFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	cleanup, err := setupTestConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to setup test config: %v", err)
+	}
+	defer cleanup()
+
+	m, err := NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	// File appears after LoadAll, e.g. created by another editor
+	content := "---\nid: external-id\ntitle: External\n---\nBody"
+	externalPath := filepath.Join(tmpDir, "external.md")
+	if err := os.WriteFile(externalPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if err := m.Save(&Snippet{ID: "external-id", Title: "External", Body: "Changed"}); err != nil {
+		t.Fatalf("Manager.Save() error = %v", err)
+	}
+
+	files := snippetFiles(t, m, "external-id")
+	if len(files) != 1 || files[0] != externalPath {
+		t.Errorf("Manager.Save() files = %v, want [%s]", files, externalPath)
+	}
+}
+
+// readCountingBackend counts the files read from the store
+type readCountingBackend struct {
+	*storage.Memory
+	reads int
+}
+
+func (b *readCountingBackend) ReadFile(path string) ([]byte, error) {
+	b.reads++
+	return b.Memory.ReadFile(path)
+}
+
+func TestManager_Save_NewSnippetAfterLoadAll(t *testing.T) {
+	backend := &readCountingBackend{Memory: storage.NewMemory("/snippets")}
+	m, err := NewManager(WithBackend(backend))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	if err := m.LoadAll(); err != nil {
+		t.Fatalf("Manager.LoadAll() error = %v", err)
+	}
+
+	reads := backend.reads
+	for _, id := range []string{"new-1", "new-2", "new-3"} {
+		if err := m.Save(&Snippet{ID: id, Title: "New " + id, Body: "Body"}); err != nil {
+			t.Fatalf("Manager.Save() error = %v", err)
+		}
+	}
+	if backend.reads != reads {
+		t.Errorf("Manager.Save() read %d files backing other snippets, want 0", backend.reads-reads)
+	}
+}
+
+func TestManager_Save_FileCreatedAfterLoadAll(t *testing.T) {
+	backend := storage.NewMemory("/snippets")
+	m, err := NewManager(WithBackend(backend))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	if err := m.LoadAll(); err != nil {
+		t.Fatalf("Manager.LoadAll() error = %v", err)
+	}
+
+	// Another program writes a snippet file after the snippets were loaded
+	external := "/snippets/from-editor.md"
+	if err := backend.WriteFile(external, []byte("---\nid: ext-id\ntitle: External\n---\nold")); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if err := m.Save(&Snippet{ID: "ext-id", Title: "External", Body: "new"}); err != nil {
+		t.Fatalf("Manager.Save() error = %v", err)
+	}
+
+	files, _ := backend.ListFiles()
+	if len(files) != 1 || files[0] != external {
+		t.Errorf("files after Save() = %v, want only %s", files, external)
+	}
+	if content, _ := backend.ReadFile(external); !strings.HasSuffix(strings.TrimSpace(string(content)), "new") {
+		t.Errorf("%s = %q, want the saved body", external, content)
+	}
+}
+
+func TestManager_LoadAll_DuplicateIDs(t *testing.T) {
+	tmpDir := t.TempDir()
+	cleanup, err := setupTestConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to setup test config: %v", err)
+	}
+	defer cleanup()
+
+	m, err := NewManager()
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+
+	files := map[string]string{
+		"a_old.md": "---\nid: dup-id\ntitle: Old\nupdated_at: 2024-01-01T00:00:00Z\n---\nold",
+		"b_new.md": "---\nid: dup-id\ntitle: New\nupdated_at: 2025-01-01T00:00:00Z\n---\nnew",
+		"c_mid.md": "---\nid: dup-id\ntitle: Mid\nupdated_at: 2024-06-01T00:00:00Z\n---\nmid",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	if err := m.LoadAll(); err != nil {
+		t.Fatalf("Manager.LoadAll() error = %v", err)
+	}
+
+	snippet, err := m.GetByID("dup-id")
+	if err != nil {
+		t.Fatalf("Manager.GetByID() error = %v", err)
+	}
+	if snippet.Title != "New" {
+		t.Errorf("Manager.LoadAll() kept %q, want most recently updated %q", snippet.Title, "New")
+	}
+	if got := m.paths["dup-id"]; filepath.Base(got) != "b_new.md" {
+		t.Errorf("Manager.LoadAll() path = %s, want b_new.md", got)
+	}
+}
+
+func TestNewManager_Options(t *testing.T) {
+	t.Run("with memory backend", func(t *testing.T) {
+		backend := storage.NewMemory("/snippets")
+		m, err := NewManager(WithBackend(backend))
+		if err != nil {
+			t.Fatalf("NewManager() error = %v", err)
+		}
+
+		if err := m.Save(&Snippet{ID: "mem-id", Title: "In Memory", Body: "Body"}); err != nil {
+			t.Fatalf("Manager.Save() error = %v", err)
+		}
+
+		files, _ := backend.ListFiles()
+		if len(files) != 1 {
+			t.Fatalf("backend has %d files, want 1", len(files))
+		}
+
+		// A second manager over the same backend sees the snippet
+		other, err := NewManager(WithBackend(backend))
+		if err != nil {
+			t.Fatalf("NewManager() error = %v", err)
+		}
+		if err := other.LoadAll(); err != nil {
+			t.Fatalf("Manager.LoadAll() error = %v", err)
+		}
+		if _, err := other.GetByID("mem-id"); err != nil {
+			t.Errorf("Manager.GetByID() error = %v", err)
+		}
+	})
+
+	t.Run("with data directory", func(t *testing.T) {
+		tmpDir := filepath.Join(t.TempDir(), "snippets")
+		m, err := NewManager(WithDataDirectory(tmpDir))
+		if err != nil {
+			t.Fatalf("NewManager() error = %v", err)
+		}
+		if got := m.Storage().GetSnippetsDir(); got != tmpDir {
+			t.Errorf("Manager.Storage().GetSnippetsDir() = %s, want %s", got, tmpDir)
+		}
+		if _, err := os.Stat(tmpDir); err != nil {
+			t.Errorf("NewManager() did not create data directory: %v", err)
+		}
+	})
+
+	t.Run("nil backend", func(t *testing.T) {
+		if _, err := NewManager(WithBackend(nil)); err == nil {
+			t.Error("NewManager(WithBackend(nil)) error = nil, want error")
+		}
+	})
+}
+
+func TestManager_Save_PreservesFrontmatter(t *testing.T) {
+	tmpDir := t.TempDir()
+	m, err := NewManager(WithDataDirectory(tmpDir))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	path := filepath.Join(tmpDir, "deploy.md")
+	content := "---\n# hand-written\nid: test-id\ntitle: Deploy\nauthor: jane\n---\nbody"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := m.LoadAll(); err != nil {
+		t.Fatalf("Manager.LoadAll() error = %v", err)
+	}
+
+	// Simulate a snippet that went through JSON, losing the parsed document
+	loaded, _ := m.GetByID("test-id")
+	fromJSON := &Snippet{
+		ID:        loaded.ID,
+		Title:     loaded.Title,
+		Body:      "new body",
+		CreatedAt: loaded.CreatedAt,
+		Revision:  loaded.Revision,
+		Extra:     loaded.Extra,
+	}
+	if err := m.Save(fromJSON); err != nil {
+		t.Fatalf("Manager.Save() error = %v", err)
+	}
+
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if !strings.HasPrefix(string(saved), "---\n# hand-written\nid: test-id\ntitle: Deploy\nauthor: jane\n") {
+		t.Errorf("Manager.Save() lost frontmatter layout:\n%s", saved)
+	}
+}
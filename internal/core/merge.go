@@ -0,0 +1,288 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrConflict is returned by Save when the snippet's file was modified on
+// disk after the snippet was read
+type ErrConflict struct {
+	ID     string
+	Path   string
+	Base   *Snippet // version the caller started from, nil if unknown
+	Theirs *Snippet // version currently on disk, nil if unparsable
+}
+
+func (e ErrConflict) Error() string {
+	return "conflict: snippet " + e.ID + " was modified on disk (" + e.Path + ")"
+}
+
+// Resolution selects how ResolveConflict settles a conflict
+type Resolution string
+
+const (
+	ResolveKeepMine   Resolution = "mine"
+	ResolveKeepTheirs Resolution = "theirs"
+	ResolveMerge      Resolution = "merge"
+)
+
+// ParseResolution parses a resolution name
+func ParseResolution(s string) (Resolution, error) {
+	switch r := Resolution(strings.ToLower(strings.TrimSpace(s))); r {
+	case ResolveKeepMine, ResolveKeepTheirs, ResolveMerge:
+		return r, nil
+	default:
+		return "", fmt.Errorf("unknown resolution %q (expected mine, theirs or merge)", s)
+	}
+}
+
+// Conflict markers written into merged bodies; the first and last are
+// followed by the name of the side whose lines they enclose
+const (
+	conflictMarkerStart = "<<<<<<< "
+	conflictMarkerSep   = "======="
+	conflictMarkerEnd   = ">>>>>>> "
+)
+
+// ResolveConflict settles a conflict between mine and the version on disk.
+//
+//   - ResolveKeepMine saves mine over the file on disk.
+//   - ResolveKeepTheirs discards mine and reloads the file from disk.
+//   - ResolveMerge merges both versions. If the merge is clean it is saved;
+//     otherwise the merged snippet, with conflict markers in the body, is
+//     returned unsaved together with the conflicting fields so the caller
+//     can let the user finish the merge and Save it.
+//
+// The returned snippet carries the disk revision, so a further concurrent
+// modification is still detected on the next Save.
+func (m *Manager) ResolveConflict(mine *Snippet, resolution Resolution) (*Snippet, []string, error) {
+	m.mu.RLock()
+	path := m.pathForID(mine.ID)
+	var base *Snippet
+	if previous := m.snippets[mine.ID]; previous != nil && previous.Revision == mine.Revision {
+		base = copySnippet(previous)
+	}
+	m.mu.RUnlock()
+
+	if path == "" {
+		return nil, nil, fmt.Errorf("snippet with ID %s not found on disk", mine.ID)
+	}
+
+	content, err := m.storage.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	theirs, err := ParseFrontmatter(content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse file on disk: %w", err)
+	}
+	theirs.Revision = contentHash(content)
+
+	switch resolution {
+	case ResolveKeepMine:
+		resolved := copySnippet(mine)
+		resolved.Revision = theirs.Revision
+		if err := m.Save(resolved); err != nil {
+			return nil, nil, err
+		}
+		return resolved, nil, nil
+
+	case ResolveKeepTheirs:
+		m.reloadPath(path)
+		return m.snapshot(theirs.ID, theirs), nil, nil
+
+	case ResolveMerge:
+		merged, conflicts := MergeSnippets(base, mine, theirs)
+		merged.Revision = theirs.Revision
+		if len(conflicts) > 0 {
+			return merged, conflicts, nil
+		}
+		if err := m.Save(merged); err != nil {
+			return nil, nil, err
+		}
+		return merged, nil, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unknown resolution %q", resolution)
+	}
+}
+
+// snapshot returns a copy of the in-memory snippet with the given ID, or
+// fallback if it is not loaded
+func (m *Manager) snapshot(id string, fallback *Snippet) *Snippet {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if s, ok := m.snippets[id]; ok {
+		return copySnippet(s)
+	}
+	return fallback
+}
+
+// MergeSnippets performs a three-way merge of two versions of a snippet that
+// diverged from base. Fields changed on only one side take that side's
+// value; tags are merged as sets; the body is merged line by line. When both
+// sides changed a scalar field differently, mine wins and the field is
+// reported as conflicting. Conflicting body hunks are wrapped in conflict
+// markers. If base is nil, every difference is treated as a conflict.
+func MergeSnippets(base, mine, theirs *Snippet) (*Snippet, []string) {
+	if base == nil {
+		base = &Snippet{ID: mine.ID}
+	}
+
+	merged := copySnippet(mine)
+	var conflicts []string
+
+	mergeScalar := func(field string, b, m, t any, set func(any)) {
+		switch {
+		case m == t, t == b:
+			// keep mine
+		case m == b:
+			set(t)
+		default:
+			conflicts = append(conflicts, field)
+		}
+	}
+	mergeScalar("title", base.Title, mine.Title, theirs.Title, func(v any) { merged.Title = v.(string) })
+	mergeScalar("language", base.Language, mine.Language, theirs.Language, func(v any) { merged.Language = v.(string) })
+	mergeScalar("is_favorite", base.IsFavorite, mine.IsFavorite, theirs.IsFavorite, func(v any) { merged.IsFavorite = v.(bool) })
+
+	merged.Tags = mergeTags(base.Tags, mine.Tags, theirs.Tags)
+
+	body, ok := mergeText(base.Body, mine.Body, theirs.Body, "mine", "theirs")
+	merged.Body = body
+	if !ok {
+		conflicts = append(conflicts, "body")
+	}
+
+	if theirs.UpdatedAt.After(merged.UpdatedAt) {
+		merged.UpdatedAt = theirs.UpdatedAt
+	}
+
+	return merged, conflicts
+}
+
+// MergeByUpdatedAt performs a three-way merge of two versions of a snippet
+// that diverged from base on different machines, where neither side is
+// preferred. Fields changed on only one side take that side's value; tags
+// are merged as sets; when both sides changed a scalar field, the version
+// with the latest updated_at wins. The body is merged line by line and
+// conflicting hunks are wrapped in conflict markers, the most recently
+// updated version first, labelled newer and older; the result reports whether the body merged
+// cleanly. If base is nil, every difference is treated as a change on both
+// sides.
+func MergeByUpdatedAt(base, a, b *Snippet) (*Snippet, bool) {
+	newer, older := a, b
+	if b.UpdatedAt.After(a.UpdatedAt) {
+		newer, older = b, a
+	}
+	if base == nil {
+		base = &Snippet{ID: newer.ID}
+	}
+
+	merged := copySnippet(newer)
+	if newer.Title == base.Title {
+		merged.Title = older.Title
+	}
+	if newer.Language == base.Language {
+		merged.Language = older.Language
+	}
+	if newer.IsFavorite == base.IsFavorite {
+		merged.IsFavorite = older.IsFavorite
+	}
+	if merged.CreatedAt.IsZero() || (!older.CreatedAt.IsZero() && older.CreatedAt.Before(merged.CreatedAt)) {
+		merged.CreatedAt = older.CreatedAt
+	}
+
+	merged.Tags = mergeTags(base.Tags, newer.Tags, older.Tags)
+
+	body, clean := mergeText(base.Body, newer.Body, older.Body, "newer", "older")
+	merged.Body = body
+
+	return merged, clean
+}
+
+// mergeTags returns the tags of mine and theirs, minus tags either side
+// removed from base. Order follows mine, then tags new in theirs.
+func mergeTags(base, mine, theirs []string) []string {
+	removed := func(tag string, side []string) bool {
+		return slices.Contains(base, tag) && !slices.Contains(side, tag)
+	}
+
+	merged := []string{}
+	for _, tag := range mine {
+		if !removed(tag, theirs) && !slices.Contains(merged, tag) {
+			merged = append(merged, tag)
+		}
+	}
+	for _, tag := range theirs {
+		if !removed(tag, mine) && !slices.Contains(merged, tag) {
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
+
+// mergeText performs a line-based three-way merge (diff3). It reports false
+// if some hunks conflict; those are wrapped in conflict markers naming the
+// sides mineName and theirsName.
+func mergeText(base, mine, theirs, mineName, theirsName string) (string, bool) {
+	if mine == theirs || theirs == base {
+		return mine, true
+	}
+	if mine == base {
+		return theirs, true
+	}
+
+	baseLines, mineLines, theirLines := splitLines(base), splitLines(mine), splitLines(theirs)
+
+	// Map base line indexes to their matches in each side
+	toMine := make(map[int]int)
+	for _, p := range matchLines(baseLines, mineLines) {
+		toMine[p[0]] = p[1]
+	}
+	toTheirs := make(map[int]int)
+	for _, p := range matchLines(baseLines, theirLines) {
+		toTheirs[p[0]] = p[1]
+	}
+
+	var out []string
+	clean := true
+	b, m, t := 0, 0, 0
+
+	emitChunk := func(bEnd, mEnd, tEnd int) {
+		baseChunk := baseLines[b:bEnd]
+		mineChunk := mineLines[m:mEnd]
+		theirChunk := theirLines[t:tEnd]
+		switch {
+		case slices.Equal(mineChunk, theirChunk), slices.Equal(theirChunk, baseChunk):
+			out = append(out, mineChunk...)
+		case slices.Equal(mineChunk, baseChunk):
+			out = append(out, theirChunk...)
+		default:
+			clean = false
+			out = append(out, conflictMarkerStart+mineName)
+			out = append(out, mineChunk...)
+			out = append(out, conflictMarkerSep)
+			out = append(out, theirChunk...)
+			out = append(out, conflictMarkerEnd+theirsName)
+		}
+	}
+
+	for i := range baseLines {
+		mi, inMine := toMine[i]
+		ti, inTheirs := toTheirs[i]
+		if !inMine || !inTheirs {
+			continue
+		}
+		// Line i is stable: unchanged in both sides
+		emitChunk(i, mi, ti)
+		out = append(out, baseLines[i])
+		b, m, t = i+1, mi+1, ti+1
+	}
+	emitChunk(len(baseLines), len(mineLines), len(theirLines))
+
+	return strings.Join(out, "\n"), clean
+}
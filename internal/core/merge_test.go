@@ -0,0 +1,316 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMergeText(t *testing.T) {
+	tests := []struct {
+		name      string
+		base      string
+		mine      string
+		theirs    string
+		want      string
+		wantClean bool
+	}{
+		{
+			name:      "only mine changed",
+			base:      "a\nb\nc",
+			mine:      "a\nB\nc",
+			theirs:    "a\nb\nc",
+			want:      "a\nB\nc",
+			wantClean: true,
+		},
+		{
+			name:      "only theirs changed",
+			base:      "a\nb\nc",
+			mine:      "a\nb\nc",
+			theirs:    "a\nb\nC",
+			want:      "a\nb\nC",
+			wantClean: true,
+		},
+		{
+			name:      "non-overlapping changes",
+			base:      "a\nb\nc\nd",
+			mine:      "A\nb\nc\nd",
+			theirs:    "a\nb\nc\nD\ne",
+			want:      "A\nb\nc\nD\ne",
+			wantClean: true,
+		},
+		{
+			name:      "same change on both sides",
+			base:      "a\nb",
+			mine:      "a\nx",
+			theirs:    "a\nx",
+			want:      "a\nx",
+			wantClean: true,
+		},
+		{
+			name:      "overlapping changes conflict",
+			base:      "a\nb\nc",
+			mine:      "a\nmine\nc",
+			theirs:    "a\ntheirs\nc",
+			want:      "a\n<<<<<<< mine\nmine\n=======\ntheirs\n>>>>>>> theirs\nc",
+			wantClean: false,
+		},
+		{
+			name:      "no common base",
+			base:      "",
+			mine:      "one",
+			theirs:    "two",
+			want:      "<<<<<<< mine\none\n=======\ntwo\n>>>>>>> theirs",
+			wantClean: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, clean := mergeText(tt.base, tt.mine, tt.theirs, "mine", "theirs")
+			if got != tt.want {
+				t.Errorf("mergeText() = %q, want %q", got, tt.want)
+			}
+			if clean != tt.wantClean {
+				t.Errorf("mergeText() clean = %v, want %v", clean, tt.wantClean)
+			}
+		})
+	}
+}
+
+func TestMergeSnippets(t *testing.T) {
+	base := &Snippet{ID: "id", Title: "Title", Tags: []string{"a", "b"}, Language: "sh", Body: "line1\nline2"}
+
+	t.Run("clean merge", func(t *testing.T) {
+		mine := copySnippet(base)
+		mine.Title = "New Title"
+		mine.Tags = []string{"a", "b", "mine"}
+		mine.Body = "LINE1\nline2"
+
+		theirs := copySnippet(base)
+		theirs.Language = "bash"
+		theirs.Tags = []string{"b", "theirs"} // removed "a"
+		theirs.Body = "line1\nline2\nline3"
+
+		merged, conflicts := MergeSnippets(base, mine, theirs)
+		if len(conflicts) != 0 {
+			t.Errorf("MergeSnippets() conflicts = %v, want none", conflicts)
+		}
+		if merged.Title != "New Title" || merged.Language != "bash" {
+			t.Errorf("MergeSnippets() title/language = %q/%q, want New Title/bash", merged.Title, merged.Language)
+		}
+		if want := []string{"b", "mine", "theirs"}; !slices.Equal(merged.Tags, want) {
+			t.Errorf("MergeSnippets() tags = %v, want %v", merged.Tags, want)
+		}
+		if want := "LINE1\nline2\nline3"; merged.Body != want {
+			t.Errorf("MergeSnippets() body = %q, want %q", merged.Body, want)
+		}
+	})
+
+	t.Run("conflicting fields", func(t *testing.T) {
+		mine := copySnippet(base)
+		mine.Title = "Mine"
+		mine.Body = "mine"
+		theirs := copySnippet(base)
+		theirs.Title = "Theirs"
+		theirs.Body = "theirs"
+
+		merged, conflicts := MergeSnippets(base, mine, theirs)
+		if want := []string{"title", "body"}; !slices.Equal(conflicts, want) {
+			t.Errorf("MergeSnippets() conflicts = %v, want %v", conflicts, want)
+		}
+		if merged.Title != "Mine" {
+			t.Errorf("MergeSnippets() title = %q, want mine to win", merged.Title)
+		}
+		if !strings.Contains(merged.Body, "<<<<<<< mine") {
+			t.Errorf("MergeSnippets() body = %q, want conflict markers", merged.Body)
+		}
+	})
+}
+
+func TestMergeByUpdatedAt(t *testing.T) {
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	base := &Snippet{ID: "id", Title: "Title", Tags: []string{"a"}, Language: "sh", Body: "line1\nline2", CreatedAt: t0, UpdatedAt: t0}
+
+	t.Run("latest updated_at wins", func(t *testing.T) {
+		local := copySnippet(base)
+		local.Title = "Local"
+		local.Language = "bash"
+		local.Tags = []string{"a", "local"}
+		local.UpdatedAt = t0.Add(2 * time.Hour)
+
+		remote := copySnippet(base)
+		remote.Title = "Remote"
+		remote.IsFavorite = true
+		remote.Tags = []string{"a", "remote"}
+		remote.Body = "line1\nline2\nline3"
+		remote.UpdatedAt = t0.Add(time.Hour)
+
+		merged, clean := MergeByUpdatedAt(base, local, remote)
+		if !clean {
+			t.Errorf("MergeByUpdatedAt() clean = false, want true")
+		}
+		if merged.Title != "Local" {
+			t.Errorf("MergeByUpdatedAt() title = %q, want the newer Local", merged.Title)
+		}
+		if merged.Language != "bash" || !merged.IsFavorite {
+			t.Errorf("MergeByUpdatedAt() language/favorite = %q/%v, want bash/true", merged.Language, merged.IsFavorite)
+		}
+		if want := []string{"a", "local", "remote"}; !slices.Equal(merged.Tags, want) {
+			t.Errorf("MergeByUpdatedAt() tags = %v, want %v", merged.Tags, want)
+		}
+		if merged.Body != remote.Body {
+			t.Errorf("MergeByUpdatedAt() body = %q, want %q", merged.Body, remote.Body)
+		}
+		if !merged.UpdatedAt.Equal(local.UpdatedAt) || !merged.CreatedAt.Equal(t0) {
+			t.Errorf("MergeByUpdatedAt() times = %v/%v, want %v/%v", merged.CreatedAt, merged.UpdatedAt, t0, local.UpdatedAt)
+		}
+
+		// The order of the sides doesn't matter
+		swapped, _ := MergeByUpdatedAt(base, remote, local)
+		if swapped.Title != "Local" {
+			t.Errorf("MergeByUpdatedAt() swapped title = %q, want Local", swapped.Title)
+		}
+	})
+
+	t.Run("conflicting body", func(t *testing.T) {
+		local := copySnippet(base)
+		local.Body = "local"
+		remote := copySnippet(base)
+		remote.Body = "remote"
+		remote.UpdatedAt = t0.Add(time.Hour)
+
+		merged, clean := MergeByUpdatedAt(base, local, remote)
+		if clean {
+			t.Error("MergeByUpdatedAt() clean = true, want false")
+		}
+		want := "<<<<<<< newer\nremote\n=======\nlocal\n>>>>>>> older"
+		if merged.Body != want {
+			t.Errorf("MergeByUpdatedAt() body = %q, want %q", merged.Body, want)
+		}
+	})
+
+	t.Run("no base", func(t *testing.T) {
+		local := &Snippet{ID: "id", Title: "Same", Tags: []string{"x"}, Body: "same", UpdatedAt: t0}
+		remote := &Snippet{ID: "id", Title: "Same", Tags: []string{"y"}, Body: "same", UpdatedAt: t0}
+
+		merged, clean := MergeByUpdatedAt(nil, local, remote)
+		if !clean || merged.Body != "same" {
+			t.Errorf("MergeByUpdatedAt() = %q, %v, want same, true", merged.Body, clean)
+		}
+		if want := []string{"x", "y"}; !slices.Equal(merged.Tags, want) {
+			t.Errorf("MergeByUpdatedAt() tags = %v, want %v", merged.Tags, want)
+		}
+	})
+}
+
+func TestManager_Save_Conflict(t *testing.T) {
+	tmpDir := t.TempDir()
+	m, err := NewManager(WithDataDirectory(tmpDir))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	original := &Snippet{ID: "id", Title: "Title", Body: "line1\nline2\nline3"}
+	if err := m.Save(original); err != nil {
+		t.Fatalf("Manager.Save() error = %v", err)
+	}
+
+	// setup edits the snippet both in memory and on disk, returning the caller's copy
+	setup := func(t *testing.T) *Snippet {
+		t.Helper()
+		if err := m.LoadAll(); err != nil {
+			t.Fatalf("Manager.LoadAll() error = %v", err)
+		}
+		mine, err := m.GetByID("id")
+		if err != nil {
+			t.Fatalf("Manager.GetByID() error = %v", err)
+		}
+		mine.Body = "line1\nline2\nline3"
+		if err := m.Save(mine); err != nil {
+			t.Fatalf("Manager.Save() error = %v", err)
+		}
+		mine.Body = "LINE1\nline2\nline3"
+
+		// Another editor changes the last line
+		path := m.paths["id"]
+		content, _ := os.ReadFile(path)
+		changed := strings.Replace(string(content), "line3", "LINE3", 1)
+		if err := os.WriteFile(path, []byte(changed), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		return mine
+	}
+
+	t.Run("save detects conflict", func(t *testing.T) {
+		mine := setup(t)
+		err := m.Save(mine)
+
+		var conflict ErrConflict
+		if !errors.As(err, &conflict) {
+			t.Fatalf("Manager.Save() error = %v, want ErrConflict", err)
+		}
+		if conflict.Theirs == nil || !strings.Contains(conflict.Theirs.Body, "LINE3") {
+			t.Errorf("ErrConflict.Theirs = %+v, want disk version", conflict.Theirs)
+		}
+		if conflict.Base == nil || conflict.Base.Body != "line1\nline2\nline3" {
+			t.Errorf("ErrConflict.Base = %+v, want loaded version", conflict.Base)
+		}
+	})
+
+	t.Run("keep mine", func(t *testing.T) {
+		mine := setup(t)
+		resolved, _, err := m.ResolveConflict(mine, ResolveKeepMine)
+		if err != nil {
+			t.Fatalf("Manager.ResolveConflict() error = %v", err)
+		}
+		if resolved.Body != "LINE1\nline2\nline3" {
+			t.Errorf("resolved body = %q, want mine", resolved.Body)
+		}
+	})
+
+	t.Run("keep theirs", func(t *testing.T) {
+		mine := setup(t)
+		resolved, _, err := m.ResolveConflict(mine, ResolveKeepTheirs)
+		if err != nil {
+			t.Fatalf("Manager.ResolveConflict() error = %v", err)
+		}
+		if resolved.Body != "line1\nline2\nLINE3" {
+			t.Errorf("resolved body = %q, want theirs", resolved.Body)
+		}
+		loaded, _ := m.GetByID("id")
+		if loaded.Body != resolved.Body {
+			t.Errorf("in-memory body = %q, want %q", loaded.Body, resolved.Body)
+		}
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		mine := setup(t)
+		resolved, conflicts, err := m.ResolveConflict(mine, ResolveMerge)
+		if err != nil {
+			t.Fatalf("Manager.ResolveConflict() error = %v", err)
+		}
+		if len(conflicts) != 0 {
+			t.Errorf("Manager.ResolveConflict() conflicts = %v, want none", conflicts)
+		}
+		if resolved.Body != "LINE1\nline2\nLINE3" {
+			t.Errorf("resolved body = %q, want merged", resolved.Body)
+		}
+
+		files, _ := filepath.Glob(filepath.Join(tmpDir, "*.md"))
+		content, _ := os.ReadFile(files[0])
+		if !strings.Contains(string(content), "LINE1\nline2\nLINE3") {
+			t.Errorf("merged snippet was not saved, file = %q", content)
+		}
+
+		// The saved revision lets the next save succeed
+		resolved.Body = "final"
+		if err := m.Save(resolved); err != nil {
+			t.Errorf("Manager.Save() after merge error = %v", err)
+		}
+	})
+}
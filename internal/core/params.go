@@ -0,0 +1,162 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Param is a placeholder in a snippet body that is filled in before the
+// snippet is executed or copied. Two syntaxes are supported:
+//
+//	<name> or <name=default>      (pet style)
+//	{{name}} or {{name:default}}  (mustache style)
+//
+// A backslash right before a placeholder keeps it as literal text, without
+// the backslash: \<div> renders as <div>, \{{ name }} as {{ name }}.
+// Bodies full of such text, e.g. HTML or Helm templates, can turn
+// placeholders off altogether with "params: false" in the frontmatter.
+type Param struct {
+	Name    string `json:"name"`
+	Default string `json:"default"`
+}
+
+var (
+	petParamPattern      = regexp.MustCompile(`<([A-Za-z_][\w.-]*)(?:=([^<>\n]*))?>`)
+	mustacheParamPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][\w.-]*)(?::([^{}\n]*?))?\s*\}\}`)
+)
+
+// ParamsKey is the frontmatter key that turns placeholders off when false
+const ParamsKey = "params"
+
+// ParamsEnabled reports whether the placeholders in the snippet's body are
+// filled in, which is the case unless its frontmatter sets params: false
+func (s *Snippet) ParamsEnabled() bool {
+	enabled, ok := s.Extra[ParamsKey].(bool)
+	return !ok || enabled
+}
+
+// paramMatch is a placeholder occurrence in a body. An escaped one starts
+// at the backslash and is literal text.
+type paramMatch struct {
+	start, end int
+	param      Param
+	escaped    bool
+}
+
+// findParams returns all placeholder occurrences in body, in order
+func findParams(body string) []paramMatch {
+	var matches []paramMatch
+	for _, pattern := range []*regexp.Regexp{petParamPattern, mustacheParamPattern} {
+		for _, loc := range pattern.FindAllStringSubmatchIndex(body, -1) {
+			m := paramMatch{start: loc[0], end: loc[1]}
+			if m.start > 0 && body[m.start-1] == '\\' {
+				m.start--
+				m.escaped = true
+			}
+			m.param.Name = body[loc[2]:loc[3]]
+			if loc[4] >= 0 {
+				m.param.Default = body[loc[4]:loc[5]]
+			}
+			matches = append(matches, m)
+		}
+	}
+
+	// Order by position; drop occurrences overlapping an earlier one
+	sort.Slice(matches, func(i, j int) bool { return matches[i].start < matches[j].start })
+	result := matches[:0]
+	end := -1
+	for _, m := range matches {
+		if m.start < end {
+			continue
+		}
+		result = append(result, m)
+		end = m.end
+	}
+	return result
+}
+
+// ExtractParams returns the placeholders in body, one per name, in order of
+// first appearance. The first default given for a name wins.
+func ExtractParams(body string) []Param {
+	var params []Param
+	seen := make(map[string]int)
+	for _, m := range findParams(body) {
+		if m.escaped {
+			continue
+		}
+		if i, ok := seen[m.param.Name]; ok {
+			if params[i].Default == "" {
+				params[i].Default = m.param.Default
+			}
+			continue
+		}
+		seen[m.param.Name] = len(params)
+		params = append(params, m.param)
+	}
+	return params
+}
+
+// RenderParams replaces the placeholders in body with values. Placeholders
+// without a value use their default; it is an error if neither exists.
+// Escaped placeholders are written without their backslash.
+func RenderParams(body string, values map[string]string) (string, error) {
+	defaults := make(map[string]string)
+	for _, p := range ExtractParams(body) {
+		defaults[p.Name] = p.Default
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range findParams(body) {
+		if m.escaped {
+			b.WriteString(body[last:m.start])
+			b.WriteString(body[m.start+1 : m.end])
+			last = m.end
+			continue
+		}
+		value, ok := values[m.param.Name]
+		if !ok {
+			value, ok = defaults[m.param.Name]
+			if !ok || value == "" {
+				return "", fmt.Errorf("no value for parameter %q", m.param.Name)
+			}
+		}
+		b.WriteString(body[last:m.start])
+		b.WriteString(value)
+		last = m.end
+	}
+	b.WriteString(body[last:])
+
+	return b.String(), nil
+}
+
+// BodySegment is a part of a snippet body: literal text, or a placeholder
+// when Param is set
+type BodySegment struct {
+	Text  string // the text as written in the body
+	Param *Param
+}
+
+// SplitBody splits body into literal text and placeholders, in order.
+// Escaped placeholders are part of the literal text.
+func SplitBody(body string) []BodySegment {
+	var segments []BodySegment
+	last := 0
+	for _, m := range findParams(body) {
+		if m.escaped {
+			continue
+		}
+		if m.start > last {
+			segments = append(segments, BodySegment{Text: body[last:m.start]})
+		}
+		param := m.param
+		segments = append(segments, BodySegment{Text: body[m.start:m.end], Param: &param})
+		last = m.end
+	}
+	if last < len(body) {
+		segments = append(segments, BodySegment{Text: body[last:]})
+	}
+	return segments
+}
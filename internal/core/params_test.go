@@ -0,0 +1,178 @@
+package core
+
+import "testing"
+
+func TestExtractParams(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []Param
+	}{
+		{
+			name: "no params",
+			body: "ls -la > out.txt < in.txt",
+			want: nil,
+		},
+		{
+			name: "pet style",
+			body: "git checkout <branch=main> && git pull origin <branch>",
+			want: []Param{{Name: "branch", Default: "main"}},
+		},
+		{
+			name: "mustache style",
+			body: "python -m http.server {{port:8080}} --bind {{ host }}",
+			want: []Param{{Name: "port", Default: "8080"}, {Name: "host"}},
+		},
+		{
+			name: "mixed styles in order",
+			body: "ssh {{user:root}}@<host> -p <port=22>",
+			want: []Param{{Name: "user", Default: "root"}, {Name: "host"}, {Name: "port", Default: "22"}},
+		},
+		{
+			name: "later default fills in",
+			body: "echo <name> <name=world>",
+			want: []Param{{Name: "name", Default: "world"}},
+		},
+		{
+			name: "default with spaces",
+			body: `git commit -m "<message=fix: typo in docs>"`,
+			want: []Param{{Name: "message", Default: "fix: typo in docs"}},
+		},
+		{
+			name: "go template is not a param",
+			body: "snipgo list --format '{{.Title}}'",
+			want: nil,
+		},
+		{
+			name: "escaped html tags",
+			body: `echo '\<div class="x"><name>\</div>'`,
+			want: []Param{{Name: "name"}},
+		},
+		{
+			name: "escaped jinja",
+			body: `echo "\{{ user }} is <user=root>"`,
+			want: []Param{{Name: "user", Default: "root"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExtractParams(tt.body)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ExtractParams() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("ExtractParams()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRenderParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		values  map[string]string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "values and defaults",
+			body:   "git checkout <branch=main> && git push origin <branch> -p {{port:22}}",
+			values: map[string]string{"branch": "dev"},
+			want:   "git checkout dev && git push origin dev -p 22",
+		},
+		{
+			name:   "default from another occurrence",
+			body:   "echo <name> <name=world>",
+			values: nil,
+			want:   "echo world world",
+		},
+		{
+			name:   "explicit empty value",
+			body:   "echo x<suffix=abc>",
+			values: map[string]string{"suffix": ""},
+			want:   "echo x",
+		},
+		{
+			name:    "missing value",
+			body:    "ssh <host>",
+			values:  nil,
+			wantErr: true,
+		},
+		{
+			name: "no params",
+			body: "echo hi",
+			want: "echo hi",
+		},
+		{
+			name:   "escaped html tags",
+			body:   `printf '\<b><text>\</b>\n' > \<file.txt=out>`,
+			values: map[string]string{"text": "bold"},
+			want:   `printf '<b>bold\</b>\n' > <file.txt=out>`,
+		},
+		{
+			name:   "escaped jinja",
+			body:   "helm template . --set a=\\{{ name }} && echo {{ name }}",
+			values: map[string]string{"name": "api"},
+			want:   "helm template . --set a={{ name }} && echo api",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderParams(tt.body, tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RenderParams() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnippet_ParamsEnabled(t *testing.T) {
+	tests := []struct {
+		name  string
+		extra map[string]any
+		want  bool
+	}{
+		{name: "default", want: true},
+		{name: "enabled", extra: map[string]any{"params": true}, want: true},
+		{name: "disabled", extra: map[string]any{"params": false}, want: false},
+		{name: "not a bool", extra: map[string]any{"params": "no"}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snippet := &Snippet{Body: "<div><p>{{ title }}</p></div>", Extra: tt.extra}
+			if got := snippet.ParamsEnabled(); got != tt.want {
+				t.Errorf("Snippet.ParamsEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitBody(t *testing.T) {
+	segments := SplitBody(`ssh <user=root>@{{host}} -p 22 \<port>`)
+	want := []BodySegment{
+		{Text: "ssh "},
+		{Text: "<user=root>", Param: &Param{Name: "user", Default: "root"}},
+		{Text: "@"},
+		{Text: "{{host}}", Param: &Param{Name: "host"}},
+		{Text: ` -p 22 \<port>`},
+	}
+	if len(segments) != len(want) {
+		t.Fatalf("SplitBody() = %d segments, want %d", len(segments), len(want))
+	}
+	for i := range want {
+		got := segments[i]
+		if got.Text != want[i].Text || (got.Param == nil) != (want[i].Param == nil) ||
+			(got.Param != nil && *got.Param != *want[i].Param) {
+			t.Errorf("SplitBody()[%d] = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
@@ -0,0 +1,384 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Query is a parsed search query. The syntax is:
+//
+//	docker compose        snippets matching both words (title, tags or body)
+//	"exact phrase"        snippets containing the phrase
+//	tag:docker            snippets tagged docker
+//	lang:yaml             snippets in a language (also language:)
+//	title:deploy          word or word prefix in the title
+//	body:kubectl          word or word prefix in the body
+//	is:fav                favorite snippets (also is:favorite)
+//	created:>2025-01-01   created after a date; also >=, <, <=, =
+//	updated:<2025-06-01   updated before a date
+//	-word, NOT word       exclude matches
+//	a OR b, a | b         either matches
+//	( ... )               grouping
+//
+// Terms separated by spaces (or AND) must all match.
+type Query struct {
+	root queryNode // nil matches everything
+}
+
+// QueryError describes a malformed query
+type QueryError struct {
+	Pos     int // byte offset in the query
+	Message string
+}
+
+func (e QueryError) Error() string {
+	return fmt.Sprintf("invalid query at position %d: %s", e.Pos+1, e.Message)
+}
+
+// queryNode is a node of a parsed query. match reports whether the snippet
+// matches and a relevance score.
+type queryNode interface {
+	match(s *Snippet, ctx *matchContext) (bool, float64)
+}
+
+// ParseQuery parses a search query
+func ParseQuery(input string) (*Query, error) {
+	tokens, err := lexQuery(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{tokens: tokens, input: input}
+	if len(tokens) == 0 {
+		return &Query{}, nil
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok != nil {
+		if tok.kind == tokRParen {
+			return nil, QueryError{Pos: tok.pos, Message: `unexpected ")"`}
+		}
+		return nil, QueryError{Pos: tok.pos, Message: fmt.Sprintf("unexpected %q", tok.text)}
+	}
+
+	return &Query{root: root}, nil
+}
+
+type tokenKind int
+
+const (
+	tokWord tokenKind = iota
+	tokPhrase
+	tokField
+	tokLParen
+	tokRParen
+	tokNot
+	tokAnd
+	tokOr
+)
+
+type queryToken struct {
+	kind  tokenKind
+	pos   int
+	text  string // word, phrase or field value
+	field string // field name for tokField
+	// quoted is set for field values given in quotes
+	quoted bool
+}
+
+// queryFields maps accepted field names to their canonical name
+var queryFields = map[string]string{
+	"tag":      "tag",
+	"tags":     "tag",
+	"lang":     "lang",
+	"language": "lang",
+	"title":    "title",
+	"body":     "body",
+	"is":       "is",
+	"created":  "created",
+	"updated":  "updated",
+	"id":       "id",
+}
+
+// lexQuery splits a query into tokens
+func lexQuery(input string) ([]queryToken, error) {
+	var tokens []queryToken
+	i := 0
+	for i < len(input) {
+		c := rune(input[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(':
+			tokens = append(tokens, queryToken{kind: tokLParen, pos: i, text: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, queryToken{kind: tokRParen, pos: i, text: ")"})
+			i++
+		case c == '|':
+			tokens = append(tokens, queryToken{kind: tokOr, pos: i, text: "|"})
+			i++
+		case c == '-' && i+1 < len(input) && !unicode.IsSpace(rune(input[i+1])):
+			tokens = append(tokens, queryToken{kind: tokNot, pos: i, text: "-"})
+			i++
+		case c == '"':
+			text, end, err := lexQuoted(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, queryToken{kind: tokPhrase, pos: i, text: text})
+			i = end
+		default:
+			start := i
+			for i < len(input) && !unicode.IsSpace(rune(input[i])) && !strings.ContainsRune(`()"|`, rune(input[i])) {
+				i++
+			}
+			word := input[start:i]
+
+			// field:value, where value may be quoted
+			if name, value, ok := strings.Cut(word, ":"); ok {
+				if field, known := queryFields[strings.ToLower(name)]; known {
+					tok := queryToken{kind: tokField, pos: start, field: field, text: value}
+					if value == "" && i < len(input) && input[i] == '"' {
+						text, end, err := lexQuoted(input, i)
+						if err != nil {
+							return nil, err
+						}
+						tok.text, tok.quoted = text, true
+						i = end
+					}
+					if tok.text == "" && !tok.quoted {
+						return nil, QueryError{Pos: start, Message: fmt.Sprintf("missing value after %q", name+":")}
+					}
+					tokens = append(tokens, tok)
+					continue
+				}
+			}
+
+			switch word {
+			case "AND":
+				tokens = append(tokens, queryToken{kind: tokAnd, pos: start, text: word})
+			case "OR":
+				tokens = append(tokens, queryToken{kind: tokOr, pos: start, text: word})
+			case "NOT":
+				tokens = append(tokens, queryToken{kind: tokNot, pos: start, text: word})
+			default:
+				tokens = append(tokens, queryToken{kind: tokWord, pos: start, text: word})
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// lexQuoted reads a double-quoted string starting at input[start] and
+// returns its content and the offset after the closing quote
+func lexQuoted(input string, start int) (string, int, error) {
+	end := strings.IndexByte(input[start+1:], '"')
+	if end < 0 {
+		return "", 0, QueryError{Pos: start, Message: "unterminated quote"}
+	}
+	return input[start+1 : start+1+end], start + end + 2, nil
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+	input  string
+}
+
+func (p *queryParser) peek() *queryToken {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+func (p *queryParser) next() *queryToken {
+	tok := p.peek()
+	if tok != nil {
+		p.pos++
+	}
+	return tok
+}
+
+// parseOr parses: and ("OR" and)*
+func (p *queryParser) parseOr() (queryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []queryNode{left}
+	for tok := p.peek(); tok != nil && tok.kind == tokOr; tok = p.peek() {
+		p.next()
+		if next := p.peek(); next == nil || next.kind == tokOr || next.kind == tokRParen {
+			return nil, QueryError{Pos: tok.pos, Message: fmt.Sprintf("missing term after %q", tok.text)}
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, right)
+	}
+
+	if len(nodes) == 1 {
+		return left, nil
+	}
+	return orNode(nodes), nil
+}
+
+// parseAnd parses: unary (["AND"] unary)*
+func (p *queryParser) parseAnd() (queryNode, error) {
+	var nodes []queryNode
+	for {
+		tok := p.peek()
+		if tok == nil || tok.kind == tokOr || tok.kind == tokRParen {
+			break
+		}
+		if tok.kind == tokAnd {
+			p.next()
+			if len(nodes) == 0 {
+				return nil, QueryError{Pos: tok.pos, Message: `missing term before "AND"`}
+			}
+			if next := p.peek(); next == nil || next.kind == tokOr || next.kind == tokRParen || next.kind == tokAnd {
+				return nil, QueryError{Pos: tok.pos, Message: `missing term after "AND"`}
+			}
+			continue
+		}
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+
+	if len(nodes) == 0 {
+		tok := p.peek()
+		if tok == nil {
+			return nil, QueryError{Pos: len(p.input), Message: "missing term"}
+		}
+		return nil, QueryError{Pos: tok.pos, Message: fmt.Sprintf("missing term before %q", tok.text)}
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return andNode(nodes), nil
+}
+
+// parseUnary parses: ("-" | "NOT") unary | primary
+func (p *queryParser) parseUnary() (queryNode, error) {
+	tok := p.peek()
+	if tok != nil && tok.kind == tokNot {
+		p.next()
+		if next := p.peek(); next == nil || next.kind == tokOr || next.kind == tokRParen || next.kind == tokAnd {
+			return nil, QueryError{Pos: tok.pos, Message: fmt.Sprintf("missing term after %q", tok.text)}
+		}
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses a group, field filter, phrase or word
+func (p *queryParser) parsePrimary() (queryNode, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokLParen:
+		if next := p.peek(); next != nil && next.kind == tokRParen {
+			return nil, QueryError{Pos: tok.pos, Message: "empty group"}
+		}
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		closing := p.next()
+		if closing == nil || closing.kind != tokRParen {
+			return nil, QueryError{Pos: tok.pos, Message: `missing ")"`}
+		}
+		return inner, nil
+	case tokPhrase:
+		return phraseNode(strings.ToLower(tok.text)), nil
+	case tokWord:
+		return textNode(tok.text), nil
+	case tokField:
+		return parseField(tok)
+	default:
+		return nil, QueryError{Pos: tok.pos, Message: fmt.Sprintf("unexpected %q", tok.text)}
+	}
+}
+
+// parseField builds the filter for a field:value token
+func parseField(tok *queryToken) (queryNode, error) {
+	value := tok.text
+	switch tok.field {
+	case "tag":
+		return tagNode(strings.ToLower(value)), nil
+	case "lang":
+		return langNode(strings.ToLower(value)), nil
+	case "title":
+		return fieldTextNode{field: "title", text: strings.ToLower(value)}, nil
+	case "body":
+		return fieldTextNode{field: "body", text: strings.ToLower(value)}, nil
+	case "id":
+		return idNode(strings.ToUpper(value)), nil
+	case "is":
+		switch strings.ToLower(value) {
+		case "fav", "favorite", "favourite", "starred":
+			return favoriteNode{}, nil
+		default:
+			return nil, QueryError{Pos: tok.pos, Message: fmt.Sprintf("unknown value %q for is: (expected fav)", value)}
+		}
+	case "created", "updated":
+		return parseDateFilter(tok)
+	default:
+		return nil, QueryError{Pos: tok.pos, Message: fmt.Sprintf("unknown field %q", tok.field)}
+	}
+}
+
+// queryDateLayouts are the accepted date formats in created:/updated:
+var queryDateLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02", "2006-01", "2006"}
+
+// parseDateFilter parses created:>2025-01-01 style filters
+func parseDateFilter(tok *queryToken) (queryNode, error) {
+	value := tok.text
+	op := "="
+	for _, candidate := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(value, candidate) {
+			op = candidate
+			value = value[len(candidate):]
+			break
+		}
+	}
+
+	for _, layout := range queryDateLayouts {
+		t, err := time.ParseInLocation(layout, value, time.Local)
+		if err != nil {
+			continue
+		}
+		// The period covered by the value, e.g. a whole day for 2025-01-01
+		var end time.Time
+		switch layout {
+		case "2006":
+			end = t.AddDate(1, 0, 0)
+		case "2006-01":
+			end = t.AddDate(0, 1, 0)
+		case "2006-01-02":
+			end = t.AddDate(0, 0, 1)
+		case "2006-01-02T15:04":
+			end = t.Add(time.Minute)
+		default:
+			end = t.Add(time.Second)
+		}
+		return dateNode{field: tok.field, op: op, start: t, end: end}, nil
+	}
+
+	return nil, QueryError{Pos: tok.pos, Message: fmt.Sprintf("invalid date %q for %s: (expected YYYY-MM-DD)", value, tok.field)}
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"snipgo/internal/storage"
+)
+
+func TestParseQuery_Errors(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantPos int
+		wantMsg string
+	}{
+		{`"unterminated`, 0, "unterminated quote"},
+		{`tag:`, 0, `missing value after "tag:"`},
+		{`is:broken`, 0, `unknown value "broken" for is:`},
+		{`docker created:>yesterday`, 7, `invalid date "yesterday"`},
+		{`(docker`, 0, `missing ")"`},
+		{`docker)`, 6, `unexpected ")"`},
+		{`()`, 0, "empty group"},
+		{`docker OR`, 7, `missing term after "OR"`},
+		{`OR docker`, 0, `missing term before "OR"`},
+		{`docker AND`, 7, `missing term after "AND"`},
+		{`NOT`, 0, `missing term after "NOT"`},
+		{`docker NOT`, 7, `missing term after "NOT"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := ParseQuery(tt.query)
+			var qerr QueryError
+			if !errors.As(err, &qerr) {
+				t.Fatalf("ParseQuery(%q) error = %v, want QueryError", tt.query, err)
+			}
+			if qerr.Pos != tt.wantPos {
+				t.Errorf("ParseQuery(%q) error position = %d, want %d", tt.query, qerr.Pos, tt.wantPos)
+			}
+			if !strings.Contains(qerr.Message, tt.wantMsg) {
+				t.Errorf("ParseQuery(%q) error = %q, want it to contain %q", tt.query, qerr.Message, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestManager_SearchQuery(t *testing.T) {
+	m, err := NewManager(WithBackend(storage.NewMemory("/snippets")))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	date := func(s string) time.Time {
+		d, err := time.ParseInLocation("2006-01-02", s, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	snippets := []*Snippet{
+		{ID: "01A", Title: "Compose up", Language: "yaml", Tags: []string{"docker", "compose"},
+			Body: "docker compose up -d", IsFavorite: true, CreatedAt: date("2025-03-01")},
+		{ID: "01B", Title: "Prune images", Language: "sh", Tags: []string{"docker"},
+			Body: "docker image prune -a", CreatedAt: date("2024-11-20")},
+		{ID: "01C", Title: "Deployment manifest", Language: "yaml", Tags: []string{"k8s"},
+			Body: "kind: Deployment\nreplicas: 3", CreatedAt: date("2025-01-01")},
+		{ID: "02D", Title: "List pods", Language: "sh", Tags: []string{"k8s", "kubectl"},
+			Body: "kubectl get pods --all-namespaces", IsFavorite: true, CreatedAt: date("2025-06-15")},
+	}
+	for _, s := range snippets {
+		m.snippets[s.ID] = s
+		m.index.add(s)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{`tag:docker`, []string{"01A", "01B"}},
+		{`tag:DOCKER lang:yaml`, []string{"01A"}},
+		{`lang:sh is:fav`, []string{"02D"}},
+		{`is:favorite`, []string{"01A", "02D"}},
+		{`created:>2025-01-01`, []string{"01A", "02D"}},
+		{`created:>=2025-01-01`, []string{"01A", "01C", "02D"}},
+		{`created:<2025-01-01`, []string{"01B"}},
+		{`created:2025-01`, []string{"01C"}},
+		{`created:2025`, []string{"01A", "01C", "02D"}},
+		{`"image prune"`, []string{"01B"}},
+		{`"image docker"`, nil},
+		{`"ker comp"`, []string{"01A"}}, // starts inside "docker"
+		{`tag:docker -compose`, []string{"01B"}},
+		{`tag:docker NOT compose`, []string{"01B"}},
+		{`tag:k8s AND lang:yaml`, []string{"01C"}},
+		{`lang:yaml OR is:fav`, []string{"01A", "01C", "02D"}},
+		{`lang:yaml | is:fav`, []string{"01A", "01C", "02D"}},
+		{`(tag:docker OR tag:kubectl) -is:fav`, []string{"01B"}},
+		{`title:"list pods"`, []string{"02D"}},
+		{`body:replicas`, []string{"01C"}},
+		{`title:deploy`, []string{"01C"}},
+		{`title:manif`, []string{"01C"}},
+		{`title:ifest`, nil}, // inside "manifest", not a word prefix
+		{`body:ompose`, nil},
+		{`id:01`, []string{"01A", "01B", "01C"}},
+		{`kubectl tag:k8s`, []string{"02D"}},
+		{`tag:missing`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			results, err := m.SearchQuery(tt.query)
+			if err != nil {
+				t.Fatalf("SearchQuery(%q) error = %v", tt.query, err)
+			}
+			var got []string
+			for _, r := range results {
+				got = append(got, r.Snippet.ID)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("SearchQuery(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManager_Search_MalformedQuery(t *testing.T) {
+	m, err := NewManager(WithBackend(storage.NewMemory("/snippets")))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	m.snippets["01A"] = &Snippet{ID: "01A", Title: "Quote", Body: `echo "hello`}
+	m.index.add(m.snippets["01A"])
+
+	if _, err := m.SearchQuery(`echo "hello`); err == nil {
+		t.Error("SearchQuery() with unterminated quote should return error")
+	}
+
+	// Search falls back to plain text
+	results := m.Search(`"hello`)
+	if len(results) != 1 || results[0].Snippet.ID != "01A" {
+		t.Errorf("Search() = %v, want the snippet containing the text", results)
+	}
+}
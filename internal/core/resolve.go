@@ -0,0 +1,91 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrNotFound is returned by Resolve when no snippet matches a reference
+var ErrNotFound = errors.New("no snippet found")
+
+// ErrAmbiguous is returned by Resolve when a reference matches several
+// snippets
+type ErrAmbiguous struct {
+	Ref        string
+	By         string     // what matched: "ID prefix", "title" or "query"
+	Candidates []*Snippet // the matching snippets, best first for a query
+}
+
+func (e ErrAmbiguous) Error() string {
+	return fmt.Sprintf("%s %q is ambiguous, matches %d snippets", e.By, e.Ref, len(e.Candidates))
+}
+
+// Resolve returns the snippet a user-supplied reference names. The reference
+// is tried, in order, as a full ID, an ID prefix, an exact title and a search
+// query, all case-insensitive; the first of these that matches anything
+// decides. It returns ErrAmbiguous if that matches several snippets, and an
+// error wrapping ErrNotFound if nothing matches.
+func (m *Manager) Resolve(ref string) (*Snippet, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, fmt.Errorf("empty snippet reference")
+	}
+
+	snippets := m.GetAll()
+	sort.Slice(snippets, func(i, j int) bool {
+		if snippets[i].Title != snippets[j].Title {
+			return snippets[i].Title < snippets[j].Title
+		}
+		return snippets[i].ID < snippets[j].ID
+	})
+
+	upper := strings.ToUpper(ref)
+	var byPrefix, byTitle []*Snippet
+	for _, snippet := range snippets {
+		id := strings.ToUpper(snippet.ID)
+		if id == upper {
+			return snippet, nil
+		}
+		if strings.HasPrefix(id, upper) {
+			byPrefix = append(byPrefix, snippet)
+		}
+		if strings.EqualFold(strings.TrimSpace(snippet.Title), ref) {
+			byTitle = append(byTitle, snippet)
+		}
+	}
+
+	if s, err := pickOne(ref, "ID prefix", byPrefix); s != nil || err != nil {
+		return s, err
+	}
+	if s, err := pickOne(ref, "title", byTitle); s != nil || err != nil {
+		return s, err
+	}
+
+	results, err := m.SearchQuery(ref)
+	if err != nil {
+		return nil, err
+	}
+	byQuery := make([]*Snippet, len(results))
+	for i, result := range results {
+		byQuery[i] = result.Snippet
+	}
+	if s, err := pickOne(ref, "query", byQuery); s != nil || err != nil {
+		return s, err
+	}
+	return nil, fmt.Errorf("%w matching %q", ErrNotFound, ref)
+}
+
+// pickOne returns the only candidate, ErrAmbiguous if there are several,
+// or nothing if there are none
+func pickOne(ref, by string, candidates []*Snippet) (*Snippet, error) {
+	switch len(candidates) {
+	case 0:
+		return nil, nil
+	case 1:
+		return candidates[0], nil
+	default:
+		return nil, ErrAmbiguous{Ref: ref, By: by, Candidates: candidates}
+	}
+}
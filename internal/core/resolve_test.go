@@ -0,0 +1,66 @@
+package core
+
+import (
+	"errors"
+	"testing"
+
+	"snipgo/internal/storage"
+)
+
+func TestManager_Resolve(t *testing.T) {
+	m, err := NewManager(WithBackend(storage.NewMemory("/snippets")))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	add := func(id, title, body string) {
+		snippet := NewSnippet(title)
+		snippet.ID = id
+		snippet.Body = body
+		if err := m.Save(snippet); err != nil {
+			t.Fatalf("Save(%s) error = %v", title, err)
+		}
+	}
+	add("01JAAAAAAAAAAAAAAAAAAAAAAA", "Docker prune", "docker system prune -af")
+	add("01JAAAABBBBBBBBBBBBBBBBBBB", "Docker logs", "docker logs -f {{container}}")
+	add("01JBCCCCCCCCCCCCCCCCCCCCCC", "Git log", "git log --oneline")
+	add("01JBDDDDDDDDDDDDDDDDDDDDDD", "git log", "git log --graph")
+
+	tests := []struct {
+		ref       string
+		want      string // ID of the resolved snippet
+		ambiguous int    // number of candidates if ambiguous
+		notFound  bool
+	}{
+		{ref: "01JAAAAAAAAAAAAAAAAAAAAAAA", want: "01JAAAAAAAAAAAAAAAAAAAAAAA"},
+		{ref: "01jaaaab", want: "01JAAAABBBBBBBBBBBBBBBBBBB"},
+		{ref: "01JAAAA", ambiguous: 2},
+		{ref: "docker prune", want: "01JAAAAAAAAAAAAAAAAAAAAAAA"},
+		{ref: "GIT LOG", ambiguous: 2},
+		{ref: "container", want: "01JAAAABBBBBBBBBBBBBBBBBBB"},
+		{ref: "docker", ambiguous: 2},
+		{ref: "kubectl", notFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := m.Resolve(tt.ref)
+
+			var ambiguous ErrAmbiguous
+			switch {
+			case tt.notFound:
+				if !errors.Is(err, ErrNotFound) {
+					t.Errorf("Resolve() error = %v, want ErrNotFound", err)
+				}
+			case tt.ambiguous > 0:
+				if !errors.As(err, &ambiguous) || len(ambiguous.Candidates) != tt.ambiguous {
+					t.Errorf("Resolve() error = %v, want ErrAmbiguous with %d candidates", err, tt.ambiguous)
+				}
+			case err != nil:
+				t.Errorf("Resolve() error = %v", err)
+			case got.ID != tt.want:
+				t.Errorf("Resolve() = %s, want %s", got.ID, tt.want)
+			}
+		})
+	}
+}
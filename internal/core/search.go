@@ -1,96 +1,313 @@
 package core
 
 import (
+	"log/slog"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/sahilm/fuzzy"
 )
 
-// SearchResult represents a search result with a score
+// SearchResult represents a search result with a score, where the query
+// matched and an excerpt of the body
 type SearchResult struct {
-	Snippet *Snippet
-	Score   int
+	Snippet *Snippet     `json:"snippet"`
+	Score   float64      `json:"score"`
+	Matches []FieldMatch `json:"matches,omitempty"`
+	Excerpt *Excerpt     `json:"excerpt,omitempty"`
 }
 
-// Search searches snippets using fuzzy search for titles and substring matching for tags/body
+// Search searches snippets with a query (see Query for the syntax). A
+// malformed query is searched as plain text; use SearchQuery to get the
+// parse error instead.
 func (m *Manager) Search(query string) []*SearchResult {
+	results, err := m.SearchQuery(query)
+	if err != nil {
+		slog.Debug("searching malformed query as plain text", "query", query, "error", err)
+		return m.search(&Query{root: textNode(query)})
+	}
+	return results
+}
+
+// SearchQuery parses a query and returns the matching snippets, best
+// matches first
+func (m *Manager) SearchQuery(query string) ([]*SearchResult, error) {
+	q, err := ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return m.search(q), nil
+}
+
+// search returns the snippets matching q sorted by score, then title
+func (m *Manager) search(q *Query) []*SearchResult {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	if query == "" {
-		// Return all snippets if query is empty
-		results := make([]*SearchResult, 0, len(m.snippets))
+	results := make([]*SearchResult, 0)
+	if q.root == nil {
 		for _, snippet := range m.snippets {
-			results = append(results, &SearchResult{
-				Snippet: copySnippet(snippet),
-				Score:   0,
-			})
+			results = append(results, &SearchResult{Snippet: copySnippet(snippet)})
+		}
+	} else {
+		ctx := &matchContext{index: m.index, scores: make(map[queryNode]map[string]float64)}
+		check := func(snippet *Snippet) {
+			if ok, score := q.root.match(snippet, ctx); ok {
+				results = append(results, &SearchResult{
+					Snippet: copySnippet(snippet),
+					Score:   score,
+				})
+			}
+		}
+
+		// Only look at snippets the index says can match, if it knows
+		if ids, ok := candidates(q.root, ctx); ok {
+			for id := range ids {
+				if snippet, exists := m.snippets[id]; exists {
+					check(snippet)
+				}
+			}
+		} else {
+			for _, snippet := range m.snippets {
+				check(snippet)
+			}
 		}
-		return results
 	}
 
-	queryLower := strings.ToLower(query)
-	results := make([]*SearchResult, 0)
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].Snippet.Title != results[j].Snippet.Title {
+			return results[i].Snippet.Title < results[j].Snippet.Title
+		}
+		return results[i].Snippet.ID < results[j].Snippet.ID
+	})
 
-	// Build a list of titles for fuzzy search
-	titles := make([]string, 0, len(m.snippets))
-	snippetMap := make(map[string]*Snippet)
-	for _, snippet := range m.snippets {
-		titles = append(titles, snippet.Title)
-		snippetMap[snippet.Title] = snippet
-	}
-
-	// Fuzzy search on titles
-	matches := fuzzy.Find(query, titles)
-	titleMatches := make(map[string]bool)
-	for _, match := range matches {
-		snippet := snippetMap[match.Str]
-		results = append(results, &SearchResult{
-			Snippet: copySnippet(snippet),
-			Score:   match.Score,
-		})
-		titleMatches[snippet.ID] = true
-	}
-
-	// Substring matching on tags and body for snippets not already matched
-	for _, snippet := range m.snippets {
-		if titleMatches[snippet.ID] {
-			continue // Already matched by title
+	if q.root != nil {
+		h := newHighlighter(q.root)
+		for _, r := range results {
+			h.apply(r)
 		}
+	}
 
-		score := 0
+	return results
+}
 
-		// Check tags
-		for _, tag := range snippet.Tags {
-			if strings.Contains(strings.ToLower(tag), queryLower) {
-				score += 10
-				break
+// fuzzyTitleScore is the score of the best title matching a term fuzzily
+// (e.g. with a typo); lower ranked fuzzy matches score less
+const fuzzyTitleScore = 0.5
+
+// matchContext carries the search index and per-term scores computed from
+// it while a query is evaluated
+type matchContext struct {
+	index  *searchIndex
+	scores map[queryNode]map[string]float64 // term node -> snippet ID -> score
+}
+
+// termScores returns the scores of the snippets matching a term or phrase,
+// computing them on first use
+func (ctx *matchContext) termScores(node queryNode, compute func() map[string]float64) map[string]float64 {
+	scores, ok := ctx.scores[node]
+	if !ok {
+		scores = compute()
+		ctx.scores[node] = scores
+	}
+	return scores
+}
+
+// candidates returns the scores of the snippets that can match node, if the
+// index can tell without evaluating every snippet. Phrases can't be found
+// that way: they may start or end inside a word.
+func candidates(node queryNode, ctx *matchContext) (map[string]float64, bool) {
+	switch n := node.(type) {
+	case textNode:
+		return ctx.termScores(n, func() map[string]float64 { return n.scores(ctx.index) }), true
+	case andNode:
+		for _, child := range n {
+			if ids, ok := candidates(child, ctx); ok {
+				return ids, true
 			}
 		}
+	}
+	return nil, false
+}
 
-		// Check body
-		if strings.Contains(strings.ToLower(snippet.Body), queryLower) {
-			score += 5
-		}
+// textNode matches a free-text term. Each word of the term has to appear
+// in the title, tags or body, exactly or as a word prefix. If no snippet
+// matches that way, titles matching the term fuzzily are used instead.
+type textNode string
+
+func (n textNode) match(s *Snippet, ctx *matchContext) (bool, float64) {
+	scores, _ := candidates(n, ctx)
+	score, ok := scores[s.ID]
+	return ok, score
+}
+
+// scores returns the scores of the snippets matching the term
+func (n textNode) scores(idx *searchIndex) map[string]float64 {
+	scores := idx.score(string(n))
+	if len(scores) > 0 {
+		return scores
+	}
+
+	// Fall back to fuzzy title matching to tolerate typos. Short terms
+	// match too many titles fuzzily to be useful.
+	scores = make(map[string]float64)
+	if len([]rune(string(n))) < 3 {
+		return scores
+	}
+	ids, titles := idx.titles()
+	rank := 0
+	for _, match := range fuzzy.Find(string(n), titles) {
+		rank++
+		scores[ids[match.Index]] = fuzzyTitleScore / float64(rank)
+	}
+	return scores
+}
+
+// phraseNode matches an exact (case-insensitive) phrase in the title, tags
+// or body
+type phraseNode string
+
+func (n phraseNode) match(s *Snippet, ctx *matchContext) (bool, float64) {
+	phrase := string(n)
+	found := strings.Contains(strings.ToLower(s.Title), phrase) ||
+		slices.ContainsFunc(s.Tags, func(tag string) bool {
+			return strings.Contains(strings.ToLower(tag), phrase)
+		}) ||
+		strings.Contains(strings.ToLower(s.Body), phrase)
+	if !found {
+		return false, 0
+	}
+
+	// Rank by the phrase's words; a phrase cut inside words may have no score
+	scores := ctx.termScores(n, func() map[string]float64 { return ctx.index.score(string(n)) })
+	return true, scores[s.ID]
+}
+
+// fieldTextNode matches words in a single field. As with free text, each
+// word of the text has to appear in the field, exactly or as a word prefix.
+// Text without letters or digits matches as a substring.
+type fieldTextNode struct {
+	field string // "title" or "body"
+	text  string
+}
 
-		// Only include if there's a match
-		if score > 0 {
-			results = append(results, &SearchResult{
-				Snippet: copySnippet(snippet),
-				Score:   score,
-			})
+func (n fieldTextNode) match(s *Snippet, ctx *matchContext) (bool, float64) {
+	value := s.Body
+	if n.field == "title" {
+		value = s.Title
+	}
+
+	terms := tokenize(n.text)
+	if len(terms) == 0 {
+		return strings.Contains(strings.ToLower(value), n.text), 0
+	}
+	words := tokenize(value)
+	for _, term := range terms {
+		if !slices.ContainsFunc(words, func(word string) bool { return strings.HasPrefix(word, term) }) {
+			return false, 0
 		}
 	}
+	return true, 0
+}
 
-	// Sort by score (higher is better)
-	// Simple bubble sort for small datasets
-	for i := 0; i < len(results); i++ {
-		for j := i + 1; j < len(results); j++ {
-			if results[i].Score < results[j].Score {
-				results[i], results[j] = results[j], results[i]
-			}
+// tagNode matches snippets with a tag, case-insensitively
+type tagNode string
+
+func (n tagNode) match(s *Snippet, ctx *matchContext) (bool, float64) {
+	return slices.ContainsFunc(s.Tags, func(tag string) bool {
+		return strings.EqualFold(tag, string(n))
+	}), 0
+}
+
+// langNode matches snippets in a language, case-insensitively
+type langNode string
+
+func (n langNode) match(s *Snippet, ctx *matchContext) (bool, float64) {
+	return strings.EqualFold(s.Language, string(n)), 0
+}
+
+// idNode matches snippets whose ID starts with a prefix
+type idNode string
+
+func (n idNode) match(s *Snippet, ctx *matchContext) (bool, float64) {
+	return strings.HasPrefix(strings.ToUpper(s.ID), string(n)), 0
+}
+
+// favoriteNode matches favorite snippets
+type favoriteNode struct{}
+
+func (favoriteNode) match(s *Snippet, ctx *matchContext) (bool, float64) {
+	return s.IsFavorite, 0
+}
+
+// dateNode compares created_at or updated_at with the period [start, end)
+type dateNode struct {
+	field      string // "created" or "updated"
+	op         string // one of > >= < <= =
+	start, end time.Time
+}
+
+func (n dateNode) match(s *Snippet, ctx *matchContext) (bool, float64) {
+	t := s.CreatedAt
+	if n.field == "updated" {
+		t = s.UpdatedAt
+	}
+
+	switch n.op {
+	case ">":
+		return !t.Before(n.end), 0
+	case ">=":
+		return !t.Before(n.start), 0
+	case "<":
+		return t.Before(n.start), 0
+	case "<=":
+		return t.Before(n.end), 0
+	default:
+		return !t.Before(n.start) && t.Before(n.end), 0
+	}
+}
+
+// notNode matches snippets its operand does not match
+type notNode struct {
+	node queryNode
+}
+
+func (n notNode) match(s *Snippet, ctx *matchContext) (bool, float64) {
+	ok, _ := n.node.match(s, ctx)
+	return !ok, 0
+}
+
+// andNode matches snippets all its operands match, summing their scores
+type andNode []queryNode
+
+func (n andNode) match(s *Snippet, ctx *matchContext) (bool, float64) {
+	total := 0.0
+	for _, node := range n {
+		ok, score := node.match(s, ctx)
+		if !ok {
+			return false, 0
 		}
+		total += score
 	}
+	return true, total
+}
 
-	return results
+// orNode matches snippets any of its operands match, summing the scores of
+// the matching operands
+type orNode []queryNode
+
+func (n orNode) match(s *Snippet, ctx *matchContext) (bool, float64) {
+	matched, total := false, 0.0
+	for _, node := range n {
+		if ok, score := node.match(s, ctx); ok {
+			matched = true
+			total += score
+		}
+	}
+	return matched, total
 }
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/oklog/ulid/v2"
+	"gopkg.in/yaml.v3"
 )
 
 // Snippet represents a code snippet with metadata
@@ -17,6 +18,16 @@ type Snippet struct {
 	CreatedAt  time.Time `yaml:"created_at" json:"created_at"`
 	UpdatedAt  time.Time `yaml:"updated_at" json:"updated_at"`
 	Body       string    `yaml:"-" json:"body"` // Body is not in frontmatter
+	// Revision identifies the file content the snippet was read from.
+	// Save uses it to detect files changed on disk since then.
+	Revision string `yaml:"-" json:"revision"`
+	// Extra holds frontmatter keys snipgo does not know about, so that
+	// hand-written metadata (author, source, ...) survives a save
+	Extra map[string]any `yaml:"-" json:"extra,omitempty"`
+
+	// frontmatter is the parsed frontmatter document, used to preserve key
+	// order and comments when the snippet is written back
+	frontmatter *yaml.Node
 }
 
 // generateID generates a ULID (26 characters, lexicographically sortable)
@@ -0,0 +1,222 @@
+package core
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TrashDirName is the directory in the data directory that deleted snippets
+// are moved to. Each deletion gets its own subdirectory named after the
+// deletion time and snippet ID, holding the file at its original path
+// relative to the data directory.
+const TrashDirName = ".trash"
+
+// TrashEntry is a deleted snippet kept in the trash
+type TrashEntry struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Path      string    `json:"path"` // where the file was before deletion
+	DeletedAt time.Time `json:"deleted_at"`
+
+	dir  string // trash subdirectory of this deletion
+	file string // the trashed file
+}
+
+// trashDir returns the trash directory of the store
+func (m *Manager) trashDir() string {
+	return filepath.Join(m.storage.GetSnippetsDir(), TrashDirName)
+}
+
+// moveToTrash moves the file of a snippet into the trash. The caller must
+// hold the storage lock.
+func (m *Manager) moveToTrash(id, path string) error {
+	content, err := m.storage.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	root := m.storage.GetSnippetsDir()
+	rel, err := filepath.Rel(root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = filepath.Base(path)
+	}
+
+	dir := filepath.Join(m.trashDir(), time.Now().UTC().Format(filenameTimestampFormat)+"_"+id)
+	target := filepath.Join(dir, rel)
+	if err := m.storage.MakeDir(filepath.Dir(target)); err != nil {
+		return fmt.Errorf("failed to create trash directory: %w", err)
+	}
+	if err := m.storage.WriteFile(target, content); err != nil {
+		return fmt.Errorf("failed to move file to trash: %w", err)
+	}
+
+	if err := m.storage.DeleteFile(path); err != nil {
+		// Don't leave a second copy behind
+		m.storage.RemoveAll(dir)
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+// ListTrash returns the deleted snippets in the trash, most recently
+// deleted first
+func (m *Manager) ListTrash() ([]*TrashEntry, error) {
+	trashDir := m.trashDir()
+	files, err := m.storage.ListDir(trashDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash: %w", err)
+	}
+
+	var entries []*TrashEntry
+	for _, file := range files {
+		rel, err := filepath.Rel(trashDir, file)
+		if err != nil {
+			continue
+		}
+		name, original, ok := strings.Cut(rel, string(filepath.Separator))
+		if !ok || !strings.HasSuffix(strings.ToLower(original), ".md") {
+			continue
+		}
+
+		entry, ok := parseTrashDirName(name)
+		if !ok {
+			continue
+		}
+		entry.dir = filepath.Join(trashDir, name)
+		entry.file = file
+		entry.Path = filepath.Join(m.storage.GetSnippetsDir(), original)
+		entry.Title = strings.TrimSuffix(filepath.Base(original), filepath.Ext(original))
+		if content, err := m.storage.ReadFile(file); err == nil {
+			if snippet, err := ParseFrontmatter(content); err == nil && snippet.Title != "" {
+				entry.Title = snippet.Title
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].DeletedAt.Equal(entries[j].DeletedAt) {
+			return entries[i].DeletedAt.After(entries[j].DeletedAt)
+		}
+		return entries[i].ID < entries[j].ID
+	})
+
+	return entries, nil
+}
+
+// parseTrashDirName parses a trash subdirectory name, "<time>_<id>"
+func parseTrashDirName(name string) (*TrashEntry, bool) {
+	if len(name) < len(filenameTimestampFormat)+2 || name[len(filenameTimestampFormat)] != '_' {
+		return nil, false
+	}
+	deletedAt, err := time.Parse(filenameTimestampFormat, name[:len(filenameTimestampFormat)])
+	if err != nil {
+		return nil, false
+	}
+	return &TrashEntry{
+		ID:        name[len(filenameTimestampFormat)+1:],
+		DeletedAt: deletedAt,
+	}, true
+}
+
+// Restore moves the most recently deleted snippet with the given ID out of
+// the trash, back to its original path. If another file took that path
+// since, the snippet ID is appended to the file name.
+func (m *Manager) Restore(id string) (*Snippet, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.snippets[id]; exists {
+		return nil, fmt.Errorf("snippet with ID %s already exists", id)
+	}
+
+	unlock, err := m.storage.Lock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock storage: %w", err)
+	}
+	defer unlock()
+
+	entries, err := m.ListTrash()
+	if err != nil {
+		return nil, err
+	}
+	var entry *TrashEntry
+	for _, e := range entries {
+		if e.ID == id {
+			entry = e
+			break
+		}
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("snippet with ID %s not found in trash", id)
+	}
+
+	content, err := m.storage.ReadFile(entry.file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	snippet, err := ParseFrontmatter(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse trashed snippet: %w", err)
+	}
+	if err := snippet.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid snippet in trash: %w", err)
+	}
+
+	path := entry.Path
+	if m.storage.FileExists(path) {
+		base := strings.TrimSuffix(path, filepath.Ext(path))
+		path = fmt.Sprintf("%s_%s.md", base, snippet.ID)
+	}
+	if err := m.storage.MakeDir(filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := m.storage.WriteFile(path, content); err != nil {
+		return nil, fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := m.storage.RemoveAll(entry.dir); err != nil {
+		return nil, fmt.Errorf("failed to remove snippet from trash: %w", err)
+	}
+
+	snippet.Revision = contentHash(content)
+	m.snippets[snippet.ID] = snippet
+	m.paths[snippet.ID] = path
+	m.index.add(snippet)
+
+	m.commit("Restore snippet '%s'", snippet.Title)
+
+	return copySnippet(snippet), nil
+}
+
+// EmptyTrash permanently deletes all snippets in the trash, along with
+// their history, and returns how many there were
+func (m *Manager) EmptyTrash() (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	unlock, err := m.storage.Lock()
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock storage: %w", err)
+	}
+	defer unlock()
+
+	entries, err := m.ListTrash()
+	if err != nil {
+		return 0, err
+	}
+	if err := m.storage.RemoveAll(m.trashDir()); err != nil {
+		return 0, fmt.Errorf("failed to empty trash: %w", err)
+	}
+	for _, entry := range entries {
+		if _, exists := m.snippets[entry.ID]; !exists {
+			if err := m.storage.RemoveAll(m.historyDir(entry.ID)); err != nil {
+				slog.Warn("failed to remove history", "id", entry.ID, "error", err)
+			}
+		}
+	}
+	return len(entries), nil
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"snipgo/internal/storage"
+)
+
+func TestManager_DeleteRestore(t *testing.T) {
+	backend := storage.NewMemory("/snippets")
+	m, err := NewManager(WithBackend(backend))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	snippet := NewSnippet("Docker prune")
+	snippet.Body = "docker system prune -af"
+	if err := m.Save(snippet); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	path := m.paths[snippet.ID]
+
+	if err := m.Delete(snippet.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if backend.FileExists(path) {
+		t.Error("Delete() left the snippet file in place")
+	}
+
+	// The trash is invisible to LoadAll
+	if err := m.LoadAll(); err != nil {
+		t.Fatalf("LoadAll() error = %v", err)
+	}
+	if len(m.GetAll()) != 0 {
+		t.Errorf("GetAll() after Delete = %d snippets, want 0", len(m.GetAll()))
+	}
+
+	entries, err := m.ListTrash()
+	if err != nil {
+		t.Fatalf("ListTrash() error = %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("ListTrash() = %d entries, want 1", len(entries))
+	}
+	entry := entries[0]
+	if entry.ID != snippet.ID || entry.Title != "Docker prune" || entry.Path != path {
+		t.Errorf("ListTrash()[0] = %+v, want ID %s, title Docker prune, path %s", entry, snippet.ID, path)
+	}
+	if entry.DeletedAt.IsZero() {
+		t.Error("ListTrash()[0].DeletedAt is zero")
+	}
+
+	restored, err := m.Restore(snippet.ID)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if restored.Body != snippet.Body {
+		t.Errorf("Restore() Body = %q, want %q", restored.Body, snippet.Body)
+	}
+	if !backend.FileExists(path) {
+		t.Error("Restore() did not write the file back to its original path")
+	}
+	if got := m.Search("prune"); len(got) != 1 {
+		t.Errorf("Search(prune) after Restore = %d results, want 1", len(got))
+	}
+	if entries, _ := m.ListTrash(); len(entries) != 0 {
+		t.Errorf("ListTrash() after Restore = %d entries, want 0", len(entries))
+	}
+
+	if _, err := m.Restore(snippet.ID); err == nil {
+		t.Error("Restore() of an existing snippet error = nil, want error")
+	}
+}
+
+func TestManager_Restore_PathTaken(t *testing.T) {
+	backend := storage.NewMemory("/snippets")
+	m, err := NewManager(WithBackend(backend))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	path := filepath.Join("/snippets", "notes.md")
+	backend.WriteFile(path, []byte("---\nid: \"01A\"\ntitle: Notes\n---\nfirst\n"))
+	if err := m.LoadAll(); err != nil {
+		t.Fatalf("LoadAll() error = %v", err)
+	}
+	if err := m.Delete("01A"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	// Another snippet takes the file name in the meantime
+	backend.WriteFile(path, []byte("---\nid: \"01B\"\ntitle: Other\n---\nsecond\n"))
+
+	if _, err := m.Restore("01A"); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if got := m.paths["01A"]; got == path || !strings.HasSuffix(got, "_01A.md") {
+		t.Errorf("Restore() path = %s, want a new file ending in _01A.md", got)
+	}
+	if content, _ := backend.ReadFile(path); !strings.Contains(string(content), "second") {
+		t.Error("Restore() overwrote the file that took its path")
+	}
+}
+
+func TestManager_EmptyTrash(t *testing.T) {
+	m, err := NewManager(WithBackend(storage.NewMemory("/snippets")))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	for _, title := range []string{"One", "Two"} {
+		snippet := NewSnippet(title)
+		if err := m.Save(snippet); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		if err := m.Delete(snippet.ID); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+	}
+
+	n, err := m.EmptyTrash()
+	if err != nil {
+		t.Fatalf("EmptyTrash() error = %v", err)
+	}
+	if n != 2 {
+		t.Errorf("EmptyTrash() = %d, want 2", n)
+	}
+	if entries, _ := m.ListTrash(); len(entries) != 0 {
+		t.Errorf("ListTrash() after EmptyTrash = %d entries, want 0", len(entries))
+	}
+	if _, err := m.Restore("missing"); err == nil {
+		t.Error("Restore() of unknown ID error = nil, want error")
+	}
+}
@@ -0,0 +1,366 @@
+// Package gitsync keeps the snippets directory in a git repository and
+// synchronizes it with a remote. It runs the git command line tool, so it
+// works with any remote git supports, including a local bare repository.
+package gitsync
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"snipgo/internal/core"
+)
+
+// DefaultRemote is the name of the remote Sync pulls from and pushes to
+const DefaultRemote = "origin"
+
+// ErrNotRepository is returned by Open when the directory is not the root
+// of a git repository
+var ErrNotRepository = errors.New("not a git repository")
+
+// gitignore keeps local state of the data directory out of the repository.
+// It is written identically everywhere, so that repositories initialized on
+// different machines don't conflict on it.
+const gitignore = `# snipgo local state
+.snipgo.lock
+` + core.TrashDirName + `/
+` + core.HistoryDirName + `/
+`
+
+// Fallback identity for commits when git has no user configured
+var fallbackIdentity = []string{
+	"GIT_AUTHOR_NAME=snipgo",
+	"GIT_AUTHOR_EMAIL=snipgo@localhost",
+	"GIT_COMMITTER_NAME=snipgo",
+	"GIT_COMMITTER_EMAIL=snipgo@localhost",
+}
+
+// Repo is a data directory kept in a git repository
+type Repo struct {
+	dir string
+	env []string // extra environment for git commands
+}
+
+// Result describes what Sync did
+type Result struct {
+	Pulled    int      // commits received from the remote
+	Pushed    int      // local commits sent to the remote
+	Merged    []string // snippet files changed on both sides and merged
+	Conflicts []string // merged snippet files left with conflict markers in the body
+}
+
+// Open returns the repository whose root is dir
+func Open(dir string) (*Repo, error) {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotRepository
+		}
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	return newRepo(dir), nil
+}
+
+// Init makes dir a git repository, if it isn't one yet, and commits the
+// snippets already in it. If remoteURL is not empty it is set as the
+// DefaultRemote.
+func Init(dir, remoteURL string) (*Repo, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	r, err := Open(dir)
+	if errors.Is(err, ErrNotRepository) {
+		r = newRepo(dir)
+		if _, err := r.git("init", "--quiet"); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		if err := os.WriteFile(path, []byte(gitignore), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write .gitignore: %w", err)
+		}
+	}
+
+	if remoteURL != "" {
+		if r.hasRemote() {
+			_, err = r.git("remote", "set-url", DefaultRemote, remoteURL)
+		} else {
+			_, err = r.git("remote", "add", DefaultRemote, remoteURL)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.Commit("Initialize snippet repository"); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func newRepo(dir string) *Repo {
+	r := &Repo{dir: dir}
+	if email, _ := r.git("config", "user.email"); email == "" {
+		r.env = fallbackIdentity
+	}
+	return r
+}
+
+// Dir returns the root directory of the repository
+func (r *Repo) Dir() string {
+	return r.dir
+}
+
+// Commit commits all changes in the repository with the given message. It
+// does nothing if there are none.
+func (r *Repo) Commit(message string) error {
+	if _, err := r.git("add", "--all"); err != nil {
+		return err
+	}
+	status, err := r.git("status", "--porcelain")
+	if err != nil {
+		return err
+	}
+	if status == "" {
+		return nil
+	}
+	_, err = r.git("commit", "--quiet", "--no-verify", "-m", message)
+	return err
+}
+
+// Sync commits pending changes, pulls the remote branch with rebase,
+// merging snippets changed on both sides, and pushes the result
+func (r *Repo) Sync() (*Result, error) {
+	if !r.hasRemote() {
+		return nil, fmt.Errorf("no remote configured, run: snipgo sync init <remote-url>")
+	}
+	if err := r.Commit("Update snippets"); err != nil {
+		return nil, err
+	}
+
+	branch, err := r.git("symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if _, err := r.git("fetch", "--quiet", DefaultRemote); err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	upstream := DefaultRemote + "/" + branch
+	if _, err := r.git("rev-parse", "--verify", "--quiet", "refs/remotes/"+upstream); err == nil {
+		if result.Pulled, err = r.count("HEAD.." + upstream); err != nil {
+			return nil, err
+		}
+		if err := r.rebase(upstream, result); err != nil {
+			return nil, err
+		}
+		if result.Pushed, err = r.count(upstream + "..HEAD"); err != nil {
+			return nil, err
+		}
+	} else if result.Pushed, err = r.count("HEAD"); err != nil {
+		// The remote doesn't have the branch yet
+		return nil, err
+	}
+
+	if result.Pushed > 0 {
+		if _, err := r.git("push", "--quiet", "--set-upstream", DefaultRemote, "HEAD:refs/heads/"+branch); err != nil {
+			return nil, fmt.Errorf("failed to push, sync again to merge new remote changes: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// rebase replays the local commits on top of upstream, merging the snippet
+// files that conflict. Other conflicts abort the rebase.
+func (r *Repo) rebase(upstream string, result *Result) error {
+	_, err := r.git("rebase", "--quiet", upstream)
+	for err != nil {
+		files, uerr := r.unmerged()
+		if uerr != nil {
+			r.git("rebase", "--abort")
+			return uerr
+		}
+		if len(files) == 0 {
+			// A merge that matches upstream leaves nothing to commit
+			if _, derr := r.git("diff", "--cached", "--quiet"); derr != nil || !r.rebasing() {
+				r.git("rebase", "--abort")
+				return fmt.Errorf("failed to rebase on %s: %w", upstream, err)
+			}
+			_, err = r.git("rebase", "--skip")
+			continue
+		}
+		for _, file := range files {
+			if merr := r.mergeFile(file, result); merr != nil {
+				r.git("rebase", "--abort")
+				return merr
+			}
+		}
+		_, err = r.git("rebase", "--continue")
+	}
+	return nil
+}
+
+// unmergedFile is a file left with conflicts, with its content at each
+// stage: the common ancestor, the upstream side and the local side. A side
+// that deleted the file has no content.
+type unmergedFile struct {
+	path                         string
+	base, remote, local          []byte
+	hasBase, hasRemote, hasLocal bool
+}
+
+// unmerged returns the files with conflicts
+func (r *Repo) unmerged() ([]*unmergedFile, error) {
+	out, err := r.git("ls-files", "--unmerged", "-z")
+	if err != nil {
+		return nil, err
+	}
+
+	byPath := make(map[string]*unmergedFile)
+	var files []*unmergedFile
+	for _, entry := range strings.Split(out, "\x00") {
+		// <mode> <object> <stage>\t<path>
+		info, path, ok := strings.Cut(entry, "\t")
+		fields := strings.Fields(info)
+		if !ok || len(fields) != 3 {
+			continue
+		}
+		f := byPath[path]
+		if f == nil {
+			f = &unmergedFile{path: path}
+			byPath[path] = f
+			files = append(files, f)
+		}
+
+		content, err := r.run("cat-file", "blob", fields[1])
+		if err != nil {
+			return nil, err
+		}
+		// During a rebase, stage 2 ("ours") is the upstream being rebased
+		// onto and stage 3 ("theirs") the local commit being replayed
+		switch fields[2] {
+		case "1":
+			f.base, f.hasBase = []byte(content), true
+		case "2":
+			f.remote, f.hasRemote = []byte(content), true
+		case "3":
+			f.local, f.hasLocal = []byte(content), true
+		}
+	}
+	return files, nil
+}
+
+// mergeFile resolves the conflicts in a snippet file. Edits win over
+// deletions; when both sides edited the snippet, they are merged with
+// core.MergeByUpdatedAt.
+func (r *Repo) mergeFile(f *unmergedFile, result *Result) error {
+	if !strings.HasSuffix(strings.ToLower(f.path), ".md") {
+		return fmt.Errorf("conflict in %s, which is not a snippet; resolve it with git in %s", f.path, r.dir)
+	}
+
+	var content []byte
+	switch {
+	case f.hasRemote && f.hasLocal:
+		remote, err := core.ParseFrontmatter(f.remote)
+		if err != nil {
+			return fmt.Errorf("failed to parse remote version of %s: %w", f.path, err)
+		}
+		local, err := core.ParseFrontmatter(f.local)
+		if err != nil {
+			return fmt.Errorf("failed to parse local version of %s: %w", f.path, err)
+		}
+		var base *core.Snippet
+		if f.hasBase {
+			base, _ = core.ParseFrontmatter(f.base)
+		}
+
+		merged, clean := core.MergeByUpdatedAt(base, local, remote)
+		if content, err = core.SerializeFrontmatter(merged); err != nil {
+			return fmt.Errorf("failed to serialize merged %s: %w", f.path, err)
+		}
+		result.Merged = append(result.Merged, f.path)
+		if !clean {
+			result.Conflicts = append(result.Conflicts, f.path)
+		}
+	case f.hasRemote:
+		content = f.remote
+	default:
+		content = f.local
+	}
+
+	if err := os.WriteFile(filepath.Join(r.dir, f.path), content, 0644); err != nil {
+		return fmt.Errorf("failed to write merged %s: %w", f.path, err)
+	}
+	_, err := r.git("add", "--", f.path)
+	return err
+}
+
+// rebasing reports whether a rebase is in progress
+func (r *Repo) rebasing() bool {
+	for _, name := range []string{"rebase-merge", "rebase-apply"} {
+		path, err := r.git("rev-parse", "--git-path", name)
+		if err != nil {
+			continue
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(r.dir, path)
+		}
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRemote reports whether the DefaultRemote is configured
+func (r *Repo) hasRemote() bool {
+	_, err := r.git("remote", "get-url", DefaultRemote)
+	return err == nil
+}
+
+// count returns the number of commits in a revision range
+func (r *Repo) count(revs string) (int, error) {
+	out, err := r.git("rev-list", "--count", revs)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(out)
+}
+
+// git runs a git command in the repository and returns its output, with
+// surrounding whitespace trimmed
+func (r *Repo) git(args ...string) (string, error) {
+	out, err := r.run(args...)
+	return strings.TrimSpace(out), err
+}
+
+// run runs a git command in the repository and returns its output
+func (r *Repo) run(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	// Never open an editor or prompt, e.g. for rebase --continue
+	cmd.Env = append(os.Environ(), "GIT_EDITOR=true", "GIT_TERMINAL_PROMPT=0")
+	cmd.Env = append(cmd.Env, r.env...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return stdout.String(), fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return stdout.String(), nil
+}
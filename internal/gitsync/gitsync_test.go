@@ -0,0 +1,163 @@
+package gitsync
+
+import (
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"snipgo/internal/core"
+)
+
+// newRemote creates a bare repository to sync with
+func newRemote(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := filepath.Join(t.TempDir(), "remote.git")
+	if out, err := exec.Command("git", "init", "--quiet", "--bare", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init --bare: %v: %s", err, out)
+	}
+	return dir
+}
+
+// newMachine initializes a data directory synced with remote and returns
+// a Manager recording its changes in the repository
+func newMachine(t *testing.T, remote string) (*Repo, *core.Manager) {
+	t.Helper()
+
+	repo, err := Init(t.TempDir(), remote)
+	if err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	m, err := core.NewManager(core.WithDataDirectory(repo.Dir()))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	m.SetCommitter(repo)
+	return repo, m
+}
+
+// sync runs Sync and reloads the snippets
+func sync(t *testing.T, repo *Repo, m *core.Manager) *Result {
+	t.Helper()
+
+	result, err := repo.Sync()
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if err := m.LoadAll(); err != nil {
+		t.Fatalf("LoadAll() error = %v", err)
+	}
+	return result
+}
+
+func TestManager_AutoCommit(t *testing.T) {
+	repo, m := newMachine(t, "")
+
+	snippet := core.NewSnippet("Docker prune")
+	snippet.Body = "docker system prune"
+	if err := m.Save(snippet); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	snippet.Body = "docker system prune -af"
+	if err := m.Save(snippet); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := m.Delete(snippet.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	log, err := repo.git("log", "--format=%s")
+	if err != nil {
+		t.Fatalf("git log: %v", err)
+	}
+	want := []string{
+		"Delete snippet 'Docker prune'",
+		"Update snippet 'Docker prune'",
+		"Add snippet 'Docker prune'",
+		"Initialize snippet repository",
+	}
+	if got := strings.Split(log, "\n"); !slices.Equal(got, want) {
+		t.Errorf("git log = %q, want %q", got, want)
+	}
+
+	// The trash and history stay out of the repository
+	if status, _ := repo.git("status", "--porcelain", "--ignored=no"); status != "" {
+		t.Errorf("git status = %q, want clean", status)
+	}
+}
+
+func TestRepo_Sync(t *testing.T) {
+	remote := newRemote(t)
+	repoA, a := newMachine(t, remote)
+	repoB, b := newMachine(t, remote)
+
+	snippet := core.NewSnippet("Docker prune")
+	snippet.Tags = []string{"docker"}
+	snippet.Body = "docker system prune\necho done"
+	if err := a.Save(snippet); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// A pushes its commits; B's first commit, identical to A's, is dropped
+	if result := sync(t, repoA, a); result.Pushed != 2 {
+		t.Errorf("Sync() on A pushed %d commits, want 2", result.Pushed)
+	}
+	if result := sync(t, repoB, b); result.Pulled == 0 || result.Pushed != 0 {
+		t.Errorf("Sync() on B pulled %d and pushed %d commits, want some and 0", result.Pulled, result.Pushed)
+	}
+	if _, err := b.GetByID(snippet.ID); err != nil {
+		t.Fatalf("GetByID() on B after Sync error = %v", err)
+	}
+
+	// Both machines change the snippet, B last
+	onA, _ := a.GetByID(snippet.ID)
+	onA.Tags = append(onA.Tags, "from-a")
+	onA.Body = "docker system prune -af\necho done"
+	if err := a.Save(onA); err != nil {
+		t.Fatalf("Save() on A error = %v", err)
+	}
+	onB, _ := b.GetByID(snippet.ID)
+	onB.Tags = append(onB.Tags, "from-b")
+	onB.Language = "bash"
+	if err := b.Save(onB); err != nil {
+		t.Fatalf("Save() on B error = %v", err)
+	}
+
+	sync(t, repoA, a)
+	result := sync(t, repoB, b)
+	if len(result.Merged) != 1 || len(result.Conflicts) != 0 {
+		t.Errorf("Sync() on B merged %v with conflicts %v, want one clean merge", result.Merged, result.Conflicts)
+	}
+	sync(t, repoA, a)
+
+	for name, m := range map[string]*core.Manager{"A": a, "B": b} {
+		got, err := m.GetByID(snippet.ID)
+		if err != nil {
+			t.Fatalf("GetByID() on %s error = %v", name, err)
+		}
+		if want := []string{"docker", "from-b", "from-a"}; !slices.Equal(got.Tags, want) {
+			t.Errorf("%s tags = %v, want %v", name, got.Tags, want)
+		}
+		if got.Language != "bash" || got.Body != onA.Body {
+			t.Errorf("%s language/body = %q/%q, want bash/%q", name, got.Language, got.Body, onA.Body)
+		}
+	}
+}
+
+func TestRepo_Sync_NoRemote(t *testing.T) {
+	repo, _ := newMachine(t, "")
+	if _, err := repo.Sync(); err == nil {
+		t.Error("Sync() without remote error = nil, want error")
+	}
+}
+
+func TestOpen_NotRepository(t *testing.T) {
+	if _, err := Open(t.TempDir()); err != ErrNotRepository {
+		t.Errorf("Open() error = %v, want ErrNotRepository", err)
+	}
+}
@@ -0,0 +1,77 @@
+// Package interop converts snippets from and to the formats of other snippet
+// managers
+package interop
+
+import (
+	"fmt"
+	"strings"
+
+	"snipgo/internal/core"
+)
+
+// Skipped is a snippet that was not imported
+type Skipped struct {
+	Snippet *core.Snippet
+	Reason  string
+}
+
+// ImportReport lists the outcome of an import
+type ImportReport struct {
+	Imported []*core.Snippet
+	Skipped  []Skipped
+}
+
+// Import saves snippets into m, skipping those without a body and those
+// whose body matches an existing snippet or one imported before them. With
+// dryRun nothing is saved, but the report is the same.
+func Import(m *core.Manager, snippets []*core.Snippet, dryRun bool) (*ImportReport, error) {
+	existing := make(map[string]*core.Snippet)
+	for _, s := range m.GetAll() {
+		existing[bodyKey(s.Body)] = s
+	}
+
+	report := &ImportReport{}
+	for _, snippet := range snippets {
+		key := bodyKey(snippet.Body)
+		if key == "" {
+			report.Skipped = append(report.Skipped, Skipped{Snippet: snippet, Reason: "empty body"})
+			continue
+		}
+		if dup, ok := existing[key]; ok {
+			report.Skipped = append(report.Skipped, Skipped{
+				Snippet: snippet,
+				Reason:  fmt.Sprintf("duplicate of '%s'", dup.Title),
+			})
+			continue
+		}
+
+		if !dryRun {
+			if err := m.Save(snippet); err != nil {
+				return report, fmt.Errorf("failed to save snippet '%s': %w", snippet.Title, err)
+			}
+		}
+		existing[key] = snippet
+		report.Imported = append(report.Imported, snippet)
+	}
+	return report, nil
+}
+
+// bodyKey normalizes a body for duplicate detection: placeholder names and
+// defaults, which don't all survive conversions, surrounding blank space
+// and trailing spaces on each line don't count
+func bodyKey(body string) string {
+	var b strings.Builder
+	for _, seg := range core.SplitBody(body) {
+		if seg.Param != nil {
+			b.WriteString("{{}}")
+		} else {
+			b.WriteString(seg.Text)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.Join(lines, "\n")
+}
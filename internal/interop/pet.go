@@ -0,0 +1,140 @@
+package interop
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"snipgo/internal/core"
+
+	"github.com/BurntSushi/toml"
+)
+
+// PetSnippet is an entry of a pet (github.com/knqyf263/pet) snippet file
+type PetSnippet struct {
+	Description string   `toml:"description"`
+	Command     string   `toml:"command"`
+	Tag         []string `toml:"tag"`
+	Output      string   `toml:"output"`
+}
+
+// petSnippetFile is the layout of pet's snippet.toml
+type petSnippetFile struct {
+	Snippets []PetSnippet `toml:"snippets"`
+}
+
+// petConfig is the part of pet's config.toml that locates the snippet file
+type petConfig struct {
+	General struct {
+		SnippetFile string `toml:"snippetfile"`
+	} `toml:"General"`
+}
+
+// DefaultPetSnippetFile returns the snippet file pet uses: the snippetfile
+// set in ~/.config/pet/config.toml, or ~/.config/pet/snippet.toml
+func DefaultPetSnippetFile() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	petDir := filepath.Join(homeDir, ".config", "pet")
+
+	var cfg petConfig
+	if _, err := toml.DecodeFile(filepath.Join(petDir, "config.toml"), &cfg); err == nil && cfg.General.SnippetFile != "" {
+		path := cfg.General.SnippetFile
+		if strings.HasPrefix(path, "~") {
+			path = filepath.Join(homeDir, path[1:])
+		}
+		return path, nil
+	}
+	return filepath.Join(petDir, "snippet.toml"), nil
+}
+
+// ReadPetFile reads the snippets of a pet snippet file
+func ReadPetFile(path string) ([]PetSnippet, error) {
+	var file petSnippetFile
+	if _, err := toml.DecodeFile(path, &file); err != nil {
+		return nil, fmt.Errorf("failed to read pet snippets: %w", err)
+	}
+	return file.Snippets, nil
+}
+
+// Snippet converts a pet snippet: the description becomes the title, the
+// command (with placeholders translated, see TranslatePetParams) the body,
+// and tags carry over. The sample output, if any, is kept in the "output"
+// frontmatter key.
+func (p PetSnippet) Snippet() *core.Snippet {
+	title := strings.TrimSpace(p.Description)
+	if title == "" {
+		title = firstLine(p.Command)
+	}
+
+	snippet := core.NewSnippet(title)
+	snippet.Body = TranslatePetParams(strings.TrimSpace(p.Command))
+	snippet.Language = "sh"
+	for _, tag := range p.Tag {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			snippet.Tags = append(snippet.Tags, tag)
+		}
+	}
+	if output := strings.TrimSpace(p.Output); output != "" {
+		snippet.Extra = map[string]any{"output": output}
+	}
+	return snippet
+}
+
+// petParamPattern matches pet placeholders: <name> and <name=default>. As
+// in pet, names have no spaces, so redirections such as <in >out are left
+// alone.
+var petParamPattern = regexp.MustCompile(`<([^<>=\s]+)(?:=([^<>\n]*))?>`)
+
+// petChoicePattern matches the options of a pet multiple choice default,
+// <name=|_one_||_two_|>
+var petChoicePattern = regexp.MustCompile(`\|_(.*?)_\|`)
+
+// invalidParamChars are the characters not allowed in snipgo placeholder names
+var invalidParamChars = regexp.MustCompile(`[^\w.-]+`)
+
+// TranslatePetParams rewrites pet placeholders in command to snipgo's
+// {{name}} and {{name:default}}. Names are made valid snipgo names, and a
+// multiple choice default keeps its first option. Defaults that can't be
+// written in braces keep the <name=default> form, which snipgo also reads.
+func TranslatePetParams(command string) string {
+	return petParamPattern.ReplaceAllStringFunc(command, func(match string) string {
+		sub := petParamPattern.FindStringSubmatch(match)
+		name := invalidParamChars.ReplaceAllString(sub[1], "_")
+		if name[0] != '_' && !isLetter(name[0]) {
+			name = "_" + name
+		}
+
+		def, hasDefault := sub[2], strings.Contains(match, "=")
+		if choices := petChoicePattern.FindAllStringSubmatch(def, -1); len(choices) > 0 {
+			def = choices[0][1]
+		}
+
+		switch {
+		case !hasDefault:
+			return "{{" + name + "}}"
+		case strings.ContainsAny(def, "{}"):
+			return "<" + name + "=" + def + ">"
+		default:
+			return "{{" + name + ":" + def + "}}"
+		}
+	})
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// firstLine returns the first non-empty line of text
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,157 @@
+package interop
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"snipgo/internal/core"
+	"snipgo/internal/storage"
+)
+
+const petSnippetTOML = `[[snippets]]
+  description = "Docker prune"
+  command = "docker system prune -af"
+  tag = ["docker", "cleanup"]
+  output = ""
+
+[[snippets]]
+  description = "Tail logs"
+  command = "kubectl logs -f <pod> -n <namespace=default>"
+  tag = ["k8s"]
+  output = "logs..."
+
+[[snippets]]
+  description = "Prune again"
+  command = "docker system prune -af  "
+  tag = []
+  output = ""
+
+[[snippets]]
+  description = "Nothing"
+  command = ""
+  tag = []
+  output = ""
+`
+
+func TestTranslatePetParams(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"echo hello", "echo hello"},
+		{"ssh <host>", "ssh {{host}}"},
+		{"ssh <user=root>@<host=example.com>", "ssh {{user:root}}@{{host:example.com}}"},
+		{"git checkout <branch=|_main_||_develop_|>", "git checkout {{branch:main}}"},
+		{"echo <file=a b.txt>", "echo {{file:a b.txt}}"},
+		{"echo <my.file>", "echo {{my.file}}"},
+		{"echo <file name=a b.txt>", "echo <file name=a b.txt>"},
+		{"echo <1st>", "echo {{_1st}}"},
+		{"echo <tmpl={{x}}>", "echo <tmpl={{x}}>"},
+		{"cmd 2>&1 < in.txt > out.txt", "cmd 2>&1 < in.txt > out.txt"},
+		{"sort <in.txt >out.txt", "sort <in.txt >out.txt"},
+		{"cat <<EOF\nx\nEOF", "cat <<EOF\nx\nEOF"},
+	}
+	for _, tt := range tests {
+		if got := TranslatePetParams(tt.command); got != tt.want {
+			t.Errorf("TranslatePetParams(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestReadPetFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snippet.toml")
+	if err := os.WriteFile(path, []byte(petSnippetTOML), 0644); err != nil {
+		t.Fatalf("Failed to write snippet file: %v", err)
+	}
+
+	pets, err := ReadPetFile(path)
+	if err != nil {
+		t.Fatalf("ReadPetFile() error = %v", err)
+	}
+	if len(pets) != 4 {
+		t.Fatalf("ReadPetFile() = %d snippets, want 4", len(pets))
+	}
+
+	snippet := pets[1].Snippet()
+	if snippet.Title != "Tail logs" || snippet.Body != "kubectl logs -f {{pod}} -n {{namespace:default}}" {
+		t.Errorf("Snippet() = %q / %q", snippet.Title, snippet.Body)
+	}
+	if !slices.Equal(snippet.Tags, []string{"k8s"}) || snippet.Extra["output"] != "logs..." {
+		t.Errorf("Snippet() tags = %v, extra = %v", snippet.Tags, snippet.Extra)
+	}
+	if err := snippet.Validate(); err != nil {
+		t.Errorf("Snippet().Validate() error = %v", err)
+	}
+}
+
+func TestPetSnippet_Redirections(t *testing.T) {
+	command := "sort -u <words.txt >sorted.txt 2>errors.log"
+	snippet := PetSnippet{Description: "Sort words", Command: command}.Snippet()
+	if snippet.Body != command {
+		t.Errorf("Snippet() body = %q, want %q unchanged", snippet.Body, command)
+	}
+}
+
+func TestImport(t *testing.T) {
+	m, err := core.NewManager(core.WithBackend(storage.NewMemory("/snippets")))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	existing := core.NewSnippet("Tail")
+	existing.Body = "kubectl logs -f {{pod}} -n {{namespace:default}}\n"
+	if err := m.Save(existing); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "snippet.toml")
+	if err := os.WriteFile(path, []byte(petSnippetTOML), 0644); err != nil {
+		t.Fatalf("Failed to write snippet file: %v", err)
+	}
+	pets, err := ReadPetFile(path)
+	if err != nil {
+		t.Fatalf("ReadPetFile() error = %v", err)
+	}
+	var snippets []*core.Snippet
+	for _, p := range pets {
+		snippets = append(snippets, p.Snippet())
+	}
+
+	// A dry run reports without saving
+	report, err := Import(m, snippets, true)
+	if err != nil {
+		t.Fatalf("Import(dryRun) error = %v", err)
+	}
+	if len(report.Imported) != 1 || len(report.Skipped) != 3 {
+		t.Errorf("Import(dryRun) = %d imported, %d skipped, want 1 and 3", len(report.Imported), len(report.Skipped))
+	}
+	if got := len(m.GetAll()); got != 1 {
+		t.Errorf("GetAll() after dry run = %d snippets, want 1", got)
+	}
+
+	report, err = Import(m, snippets, false)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if len(report.Imported) != 1 || report.Imported[0].Title != "Docker prune" {
+		t.Errorf("Import() imported = %v, want Docker prune", report.Imported)
+	}
+	reasons := make(map[string]string)
+	for _, s := range report.Skipped {
+		reasons[s.Snippet.Title] = s.Reason
+	}
+	want := map[string]string{
+		"Tail logs":   "duplicate of 'Tail'",
+		"Prune again": "duplicate of 'Docker prune'",
+		"Nothing":     "empty body",
+	}
+	for title, reason := range want {
+		if reasons[title] != reason {
+			t.Errorf("Import() skipped %q reason = %q, want %q", title, reasons[title], reason)
+		}
+	}
+	if got := len(m.GetAll()); got != 2 {
+		t.Errorf("GetAll() after Import = %d snippets, want 2", got)
+	}
+}
@@ -0,0 +1,552 @@
+package interop
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"snipgo/internal/core"
+)
+
+// VSCodeSnippet is an entry of a VS Code snippet file (.code-snippets, or
+// <language>.json for language specific snippets)
+type VSCodeSnippet struct {
+	Prefix      vscodeStrings `json:"prefix"`
+	Body        vscodeLines   `json:"body"`
+	Description string        `json:"description,omitempty"`
+	Scope       string        `json:"scope,omitempty"`
+}
+
+// vscodeStrings is a string or a list of strings; it marshals a single
+// string as a string
+type vscodeStrings []string
+
+func (s *vscodeStrings) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*s = vscodeStrings{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*s = many
+	return nil
+}
+
+func (s vscodeStrings) MarshalJSON() ([]byte, error) {
+	if len(s) == 1 {
+		return json.Marshal(s[0])
+	}
+	return json.Marshal([]string(s))
+}
+
+// vscodeLines is a snippet body: a list of lines, or a single string
+type vscodeLines []string
+
+func (l *vscodeLines) UnmarshalJSON(data []byte) error {
+	return (*vscodeStrings)(l).UnmarshalJSON(data)
+}
+
+// Languages whose snipgo and VS Code names differ. VS Code names are
+// language identifiers, see https://code.visualstudio.com/docs/languages/identifiers
+var vscodeLanguages = map[string]string{
+	"sh":     "shellscript",
+	"bash":   "shellscript",
+	"zsh":    "shellscript",
+	"shell":  "shellscript",
+	"js":     "javascript",
+	"ts":     "typescript",
+	"py":     "python",
+	"yml":    "yaml",
+	"md":     "markdown",
+	"ps1":    "powershell",
+	"pwsh":   "powershell",
+	"golang": "go",
+}
+
+// VSCodeLanguage returns the VS Code language identifier of a snipgo
+// language
+func VSCodeLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if id, ok := vscodeLanguages[language]; ok {
+		return id
+	}
+	return language
+}
+
+// languageFromVSCode returns the snipgo language of a VS Code language
+// identifier
+func languageFromVSCode(id string) string {
+	switch id = strings.ToLower(strings.TrimSpace(id)); id {
+	case "shellscript":
+		return "sh"
+	default:
+		return id
+	}
+}
+
+// ReadVSCodeFile reads the snippets of a VS Code snippet file, keyed by
+// name. Comments and trailing commas, which VS Code allows, are accepted.
+func ReadVSCodeFile(path string) (map[string]VSCodeSnippet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read VS Code snippets: %w", err)
+	}
+
+	var snippets map[string]VSCodeSnippet
+	if err := json.Unmarshal(stripJSONC(data), &snippets); err != nil {
+		return nil, fmt.Errorf("failed to parse VS Code snippets: %w", err)
+	}
+	return snippets, nil
+}
+
+// VSCodeFileLanguage returns the language of a language specific snippet
+// file, named after the language (e.g. go.json), or "" for a global
+// .code-snippets file
+func VSCodeFileLanguage(path string) string {
+	if !strings.EqualFold(filepath.Ext(path), ".json") {
+		return ""
+	}
+	return languageFromVSCode(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+}
+
+// Snippet converts a VS Code snippet named name. The body tab stops are
+// translated (see FromVSCodeBody) and the first scope, or language if the
+// snippet has none, becomes the language. The prefix, and the description
+// if it differs from the name, are kept in the "prefix" and "description"
+// frontmatter keys so that an export gives them back.
+func (v VSCodeSnippet) Snippet(name, language string) *core.Snippet {
+	snippet := core.NewSnippet(strings.TrimSpace(name))
+	snippet.Body = FromVSCodeBody(strings.Join(v.Body, "\n"))
+
+	scopes := strings.Split(v.Scope, ",")
+	if scope := strings.TrimSpace(scopes[0]); scope != "" {
+		language = languageFromVSCode(scope)
+	}
+	snippet.Language = language
+
+	extra := make(map[string]any)
+	switch len(v.Prefix) {
+	case 0:
+	case 1:
+		extra["prefix"] = v.Prefix[0]
+	default:
+		prefixes := make([]any, len(v.Prefix))
+		for i, p := range v.Prefix {
+			prefixes[i] = p
+		}
+		extra["prefix"] = prefixes
+	}
+	if v.Description != "" && v.Description != snippet.Title {
+		extra["description"] = v.Description
+	}
+	if len(scopes) > 1 {
+		extra["scope"] = v.Scope
+	}
+	if len(extra) > 0 {
+		snippet.Extra = extra
+	}
+	return snippet
+}
+
+// ExportVSCode converts snippets to the content of a .code-snippets file.
+// Snippets are keyed by title, made unique with a number when needed. The
+// prefix is the "prefix" frontmatter key if set, otherwise derived from the
+// title, and the scope comes from the language.
+func ExportVSCode(snippets []*core.Snippet) ([]byte, error) {
+	sorted := make([]*core.Snippet, len(snippets))
+	copy(sorted, snippets)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Title < sorted[j].Title })
+
+	out := make(map[string]VSCodeSnippet, len(sorted))
+	for _, s := range sorted {
+		name := s.Title
+		for n := 2; ; n++ {
+			if _, taken := out[name]; !taken {
+				break
+			}
+			name = fmt.Sprintf("%s (%d)", s.Title, n)
+		}
+
+		v := VSCodeSnippet{
+			Prefix:      extraStrings(s.Extra["prefix"]),
+			Body:        strings.Split(ToVSCodeBody(s.Body), "\n"),
+			Description: s.Title,
+			Scope:       VSCodeLanguage(s.Language),
+		}
+		if len(v.Prefix) == 0 {
+			v.Prefix = vscodeStrings{prefixFromTitle(s.Title)}
+		}
+		if description, ok := s.Extra["description"].(string); ok && description != "" {
+			v.Description = description
+		}
+		if scope, ok := s.Extra["scope"].(string); ok && scope != "" {
+			v.Scope = scope
+		}
+		out[name] = v
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return nil, fmt.Errorf("failed to encode VS Code snippets: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// extraStrings reads a frontmatter value that is a string or a list of
+// strings
+func extraStrings(value any) vscodeStrings {
+	switch v := value.(type) {
+	case string:
+		if v != "" {
+			return vscodeStrings{v}
+		}
+	case []any:
+		var out vscodeStrings
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+var nonWordChars = regexp.MustCompile(`[^\pL\pN]+`)
+
+// prefixFromTitle derives a VS Code prefix from a title: "Docker prune"
+// becomes "docker-prune"
+func prefixFromTitle(title string) string {
+	prefix := strings.Trim(nonWordChars.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if prefix == "" {
+		return "snippet"
+	}
+	return prefix
+}
+
+// ToVSCodeBody converts a snipgo body to VS Code snippet syntax. Each
+// placeholder name becomes a tab stop, numbered in order of appearance,
+// showing its default or else its name. Text is escaped so VS Code inserts
+// it as is.
+func ToVSCodeBody(body string) string {
+	stops := make(map[string]int)
+	var b strings.Builder
+	for _, seg := range core.SplitBody(body) {
+		if seg.Param == nil {
+			b.WriteString(escapeVSCode(seg.Text))
+			continue
+		}
+
+		if n, ok := stops[seg.Param.Name]; ok {
+			fmt.Fprintf(&b, "${%d}", n)
+			continue
+		}
+		n := len(stops) + 1
+		stops[seg.Param.Name] = n
+		text := seg.Param.Default
+		if text == "" {
+			text = seg.Param.Name
+		}
+		fmt.Fprintf(&b, "${%d:%s}", n, escapeVSCode(text))
+	}
+	return b.String()
+}
+
+var vscodeEscaper = strings.NewReplacer(`\`, `\\`, `$`, `\$`, `}`, `\}`)
+
+func escapeVSCode(text string) string {
+	return vscodeEscaper.Replace(text)
+}
+
+// vscodeNode is a parsed element of a VS Code snippet body: literal text,
+// or a tab stop with optional placeholder content or choices
+type vscodeNode struct {
+	text     string
+	stop     int // tab stop number, -1 for text
+	children []vscodeNode
+	choices  []string
+}
+
+// validParamName matches the names snipgo accepts for placeholders
+var validParamName = regexp.MustCompile(`^[A-Za-z_][\w.-]*$`)
+
+// FromVSCodeBody converts a VS Code snippet body to a snipgo body. Tab stops
+// become placeholders: ${1:name} becomes {{name}} when the placeholder text
+// is a valid name, and {{p1:text}} otherwise; choices default to their
+// first option. Mirrors of a tab stop share its placeholder, the final
+// cursor position $0 is dropped, and variables such as $TM_FILENAME are kept
+// as text.
+func FromVSCodeBody(body string) string {
+	nodes, _ := parseVSCode(body, 0, false)
+
+	// Name tab stops after the first occurrence that has content
+	var order []int
+	content := make(map[int]*vscodeNode)
+	var collect func([]vscodeNode)
+	collect = func(nodes []vscodeNode) {
+		for i := range nodes {
+			n := &nodes[i]
+			if n.stop < 0 {
+				continue
+			}
+			if _, seen := content[n.stop]; !seen {
+				order = append(order, n.stop)
+				content[n.stop] = nil
+			}
+			if content[n.stop] == nil && (n.children != nil || n.choices != nil) {
+				content[n.stop] = n
+			}
+			collect(n.children)
+		}
+	}
+	collect(nodes)
+
+	type param struct{ name, def string }
+	params := make(map[int]param)
+	used := make(map[string]bool)
+	for _, stop := range order {
+		if stop == 0 {
+			continue
+		}
+		var text string
+		if n := content[stop]; n != nil {
+			text = placeholderText(*n)
+		}
+		p := param{name: "p" + strconv.Itoa(stop), def: text}
+		if validParamName.MatchString(text) && !used[text] {
+			p = param{name: text}
+		}
+		if strings.ContainsAny(p.def, "{}\n") {
+			p.def = ""
+		}
+		used[p.name] = true
+		params[stop] = p
+	}
+
+	var b strings.Builder
+	written := make(map[int]bool)
+	for _, n := range nodes {
+		switch {
+		case n.stop < 0:
+			b.WriteString(n.text)
+		case n.stop == 0:
+			b.WriteString(placeholderText(n))
+		case written[n.stop] || params[n.stop].def == "":
+			b.WriteString("{{" + params[n.stop].name + "}}")
+		default:
+			b.WriteString("{{" + params[n.stop].name + ":" + params[n.stop].def + "}}")
+		}
+		if n.stop > 0 {
+			written[n.stop] = true
+		}
+	}
+	return b.String()
+}
+
+// placeholderText returns the text VS Code initially inserts for a node
+func placeholderText(n vscodeNode) string {
+	if n.stop < 0 {
+		return n.text
+	}
+	if len(n.choices) > 0 {
+		return n.choices[0]
+	}
+	var b strings.Builder
+	for _, child := range n.children {
+		b.WriteString(placeholderText(child))
+	}
+	return b.String()
+}
+
+// parseVSCode parses a VS Code snippet body from position i. Inside a
+// placeholder (nested) it stops after the closing brace. It returns the
+// nodes and the position after them.
+func parseVSCode(s string, i int, nested bool) ([]vscodeNode, int) {
+	var nodes []vscodeNode
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, vscodeNode{text: text.String(), stop: -1})
+			text.Reset()
+		}
+	}
+
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte(`$}\`, s[i+1]) >= 0:
+			text.WriteByte(s[i+1])
+			i += 2
+
+		case c == '}' && nested:
+			flush()
+			return nodes, i + 1
+
+		case c == '$' && i+1 < len(s) && isDigit(s[i+1]):
+			j := i + 1
+			for j < len(s) && isDigit(s[j]) {
+				j++
+			}
+			flush()
+			stop, _ := strconv.Atoi(s[i+1 : j])
+			nodes = append(nodes, vscodeNode{stop: stop})
+			i = j
+
+		case c == '$' && i+2 < len(s) && s[i+1] == '{' && isDigit(s[i+2]):
+			j := i + 2
+			for j < len(s) && isDigit(s[j]) {
+				j++
+			}
+			stop, _ := strconv.Atoi(s[i+2 : j])
+			node, end, ok := parseTabStop(s, j, stop)
+			if !ok {
+				text.WriteString(s[i:j])
+				i = j
+				continue
+			}
+			flush()
+			nodes = append(nodes, node)
+			i = end
+
+		case c == '$' && i+1 < len(s) && s[i+1] == '{':
+			// A variable, ${NAME...}: keep it as text
+			end := matchingBrace(s, i+1)
+			text.WriteString(s[i:end])
+			i = end
+
+		default:
+			text.WriteByte(c)
+			i++
+		}
+	}
+	flush()
+	return nodes, i
+}
+
+// parseTabStop parses the rest of a tab stop after ${N, at position i
+func parseTabStop(s string, i, stop int) (vscodeNode, int, bool) {
+	node := vscodeNode{stop: stop}
+	switch {
+	case i < len(s) && s[i] == '}':
+		return node, i + 1, true
+
+	case i < len(s) && s[i] == ':':
+		children, end := parseVSCode(s, i+1, true)
+		node.children = children
+		if node.children == nil {
+			node.children = []vscodeNode{}
+		}
+		return node, end, true
+
+	case i < len(s) && s[i] == '|':
+		end := strings.Index(s[i+1:], "|}")
+		if end < 0 {
+			return node, i, false
+		}
+		node.choices = splitUnescaped(s[i+1:i+1+end], ',')
+		return node, i + 1 + end + 2, true
+	}
+	return node, i, false
+}
+
+// splitUnescaped splits s on sep, unescaping backslash escapes
+func splitUnescaped(s string, sep byte) []string {
+	var parts []string
+	var cur strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			i++
+			cur.WriteByte(s[i])
+		case s[i] == sep:
+			parts = append(parts, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteByte(s[i])
+		}
+	}
+	return append(parts, cur.String())
+}
+
+// matchingBrace returns the position after the brace closing the one at
+// position open, or len(s) if it is not closed
+func matchingBrace(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(s)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// stripJSONC removes comments and trailing commas from JSON with comments
+func stripJSONC(data []byte) []byte {
+	var out []byte
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if inString {
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			i--
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				i = len(data)
+			} else {
+				i += 2 + end + 1
+			}
+		case c == ']' || c == '}':
+			// Drop a comma before the closing bracket
+			trimmed := bytes.TrimRight(out, " \t\r\n")
+			if len(trimmed) > 0 && trimmed[len(trimmed)-1] == ',' {
+				out = append(trimmed[:len(trimmed)-1], out[len(trimmed):]...)
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
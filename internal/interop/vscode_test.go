@@ -0,0 +1,172 @@
+package interop
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"snipgo/internal/core"
+	"snipgo/internal/storage"
+)
+
+func TestFromVSCodeBody(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{"fmt.Println()", "fmt.Println()"},
+		{"for ${1:i} := 0; $1 < ${2:n}; $1++ {\n\t$0\n}", "for {{i}} := 0; {{i}} < {{n}}; {{i}}++ {\n\t\n}"},
+		{"ssh ${1:root@example.com} -p ${2:22}", "ssh {{p1:root@example.com}} -p {{p2:22}}"},
+		{"git checkout ${1|main,develop|}", "git checkout {{main}}"},
+		{"echo $1 ${1:name}", "echo {{name}} {{name}}"},
+		{"echo ${1:name} ${2:name}", "echo {{name}} {{p2:name}}"},
+		{"cost: \\$5 \\} \\\\", "cost: $5 } \\"},
+		{"file: $TM_FILENAME ${TM_LINE_NUMBER:1}", "file: $TM_FILENAME ${TM_LINE_NUMBER:1}"},
+		{"${1:outer ${2:inner}}", "{{p1:outer inner}}"},
+		{"$3 and $3", "{{p3}} and {{p3}}"},
+	}
+	for _, tt := range tests {
+		if got := FromVSCodeBody(tt.body); got != tt.want {
+			t.Errorf("FromVSCodeBody(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestToVSCodeBody(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{"echo $HOME", "echo \\$HOME"},
+		{"ssh <user=root>@{{host}} && echo {{host}}", "ssh ${1:root}@${2:host} && echo ${2}"},
+		{"awk '{print $1}'", "awk '{print \\$1\\}'"},
+	}
+	for _, tt := range tests {
+		if got := ToVSCodeBody(tt.body); got != tt.want {
+			t.Errorf("ToVSCodeBody(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+
+	// Round trip
+	body := "kubectl port-forward {{pod}} {{port:8080}} | grep '$x'"
+	want := "kubectl port-forward {{pod}} {{p2:8080}} | grep '$x'"
+	if got := FromVSCodeBody(ToVSCodeBody(body)); got != want {
+		t.Errorf("FromVSCodeBody(ToVSCodeBody(%q)) = %q, want %q", body, got, want)
+	}
+}
+
+func TestReadVSCodeFile(t *testing.T) {
+	content := `{
+	// Comments and trailing commas are allowed
+	"Print to console": {
+		"prefix": ["log", "cl"],
+		"body": [
+			"console.log('${1:msg}');",
+			"$0",
+		],
+		"description": "Log output to console",
+		"scope": "javascript,typescript", /* both */
+	},
+	"Handle error": {
+		"prefix": "iferr",
+		"body": "if err != nil {\n\treturn ${1:err}\n}",
+	},
+}`
+	path := filepath.Join(t.TempDir(), "go.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write snippet file: %v", err)
+	}
+
+	snippets, err := ReadVSCodeFile(path)
+	if err != nil {
+		t.Fatalf("ReadVSCodeFile() error = %v", err)
+	}
+	if len(snippets) != 2 {
+		t.Fatalf("ReadVSCodeFile() = %d snippets, want 2", len(snippets))
+	}
+
+	language := VSCodeFileLanguage(path)
+	log := snippets["Print to console"].Snippet("Print to console", language)
+	if log.Body != "console.log('{{msg}}');\n" || log.Language != "javascript" {
+		t.Errorf("Snippet() body/language = %q/%q", log.Body, log.Language)
+	}
+	if log.Extra["description"] != "Log output to console" || log.Extra["scope"] != "javascript,typescript" {
+		t.Errorf("Snippet() extra = %v", log.Extra)
+	}
+
+	iferr := snippets["Handle error"].Snippet("Handle error", language)
+	if iferr.Language != "go" || iferr.Extra["prefix"] != "iferr" {
+		t.Errorf("Snippet() language/prefix = %q/%v, want go/iferr", iferr.Language, iferr.Extra["prefix"])
+	}
+	if err := iferr.Validate(); err != nil {
+		t.Errorf("Snippet().Validate() error = %v", err)
+	}
+}
+
+func TestImport_VSCodeRoundTrip(t *testing.T) {
+	m, err := core.NewManager(core.WithBackend(storage.NewMemory("/snippets")))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	snippet := core.NewSnippet("SSH")
+	snippet.Body = "ssh <user=root>@{{host}}"
+	if err := m.Save(snippet); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	data, err := ExportVSCode(m.GetAll())
+	if err != nil {
+		t.Fatalf("ExportVSCode() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "snipgo.code-snippets")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write snippet file: %v", err)
+	}
+	entries, err := ReadVSCodeFile(path)
+	if err != nil {
+		t.Fatalf("ReadVSCodeFile() error = %v", err)
+	}
+
+	// Placeholders come back renamed, but the snippet is still a duplicate
+	imported := entries["SSH"].Snippet("SSH", "")
+	report, err := Import(m, []*core.Snippet{imported}, false)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if len(report.Skipped) != 1 || len(report.Imported) != 0 {
+		t.Errorf("Import() = %d imported, %d skipped, want the exported snippet skipped", len(report.Imported), len(report.Skipped))
+	}
+}
+
+func TestExportVSCode(t *testing.T) {
+	a := core.NewSnippet("Docker prune")
+	a.Language = "bash"
+	a.Body = "docker system prune {{flags:-af}}"
+	b := core.NewSnippet("Docker prune")
+	b.Body = "docker image prune"
+	b.Extra = map[string]any{"prefix": []any{"dip", "dimg"}, "description": "Prune images"}
+
+	data, err := ExportVSCode([]*core.Snippet{a, b})
+	if err != nil {
+		t.Fatalf("ExportVSCode() error = %v", err)
+	}
+
+	var got map[string]struct {
+		Prefix      any      `json:"prefix"`
+		Body        []string `json:"body"`
+		Description string   `json:"description"`
+		Scope       string   `json:"scope"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("ExportVSCode() produced invalid JSON: %v\n%s", err, data)
+	}
+
+	first, second := got["Docker prune"], got["Docker prune (2)"]
+	if first.Prefix != "docker-prune" || first.Scope != "shellscript" || first.Body[0] != "docker system prune ${1:-af}" {
+		t.Errorf("ExportVSCode() first = %+v", first)
+	}
+	if prefixes, ok := second.Prefix.([]any); !ok || len(prefixes) != 2 || second.Description != "Prune images" || second.Scope != "" {
+		t.Errorf("ExportVSCode() second = %+v", second)
+	}
+}
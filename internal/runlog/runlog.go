@@ -0,0 +1,132 @@
+// Package runlog records the snippets run by exec in an append-only JSONL
+// file, one JSON object per line
+package runlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Entry is a run of a snippet
+type Entry struct {
+	// Number is the position of the run in the log, 1 for the most recent;
+	// it is set by Read and not stored
+	Number int `json:"-"`
+
+	SnippetID   string            `json:"snippet_id"`
+	Title       string            `json:"title"`
+	Language    string            `json:"language,omitempty"`
+	Interpreter string            `json:"interpreter"`
+	Command     string            `json:"command"` // body with its placeholders filled in
+	Vars        map[string]string `json:"vars,omitempty"`
+	Env         []string          `json:"env,omitempty"` // KEY=value pairs set for the run
+	Cwd         string            `json:"cwd"`
+	Timeout     string            `json:"timeout,omitempty"`
+	Start       time.Time         `json:"start"`
+	DurationMS  int64             `json:"duration_ms"`
+	ExitCode    int               `json:"exit_code"`
+	TimedOut    bool              `json:"timed_out,omitempty"`
+}
+
+// Duration returns how long the run took
+func (e *Entry) Duration() time.Duration {
+	return time.Duration(e.DurationMS) * time.Millisecond
+}
+
+// Log is a run log file
+type Log struct {
+	path string
+}
+
+// New returns the log stored at path, which is created on the first Append
+func New(path string) *Log {
+	return &Log{path: path}
+}
+
+// Path returns the path of the log file
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append adds a run at the end of the log
+func (l *Log) Append(entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode run: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create run log directory: %w", err)
+	}
+	// Commands and variables may be sensitive, the log is private. A line
+	// written in one call with O_APPEND doesn't interleave with others.
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open run log: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write run log: %w", err)
+	}
+	return f.Close()
+}
+
+// Read returns the runs in the log, most recent first. Lines that can't be
+// decoded, such as one cut short by a crash, are skipped.
+func (l *Log) Read() ([]*Entry, error) {
+	f, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open run log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []*Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		entry := &Entry{}
+		if err := json.Unmarshal([]byte(line), entry); err != nil {
+			slog.Warn("skipping invalid run log line", "path", l.path, "line", lineNumber, "error", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read run log: %w", err)
+	}
+
+	// Most recent first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	for i, entry := range entries {
+		entry.Number = i + 1
+	}
+	return entries, nil
+}
+
+// Get returns the run numbered n, 1 being the most recent
+func (l *Log) Get(n int) (*Entry, error) {
+	entries, err := l.Read()
+	if err != nil {
+		return nil, err
+	}
+	if n < 1 || n > len(entries) {
+		return nil, fmt.Errorf("no run %d in the log (%d runs)", n, len(entries))
+	}
+	return entries[n-1], nil
+}
@@ -0,0 +1,74 @@
+package runlog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "runs.jsonl")
+	log := New(path)
+
+	entries, err := log.Read()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Read() on a missing log = %v, %v, want no runs", entries, err)
+	}
+
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i, command := range []string{"echo one", "exit 3"} {
+		entry := &Entry{
+			SnippetID:   "01ABC",
+			Title:       "Test",
+			Interpreter: "sh",
+			Command:     command,
+			Vars:        map[string]string{"n": "1"},
+			Cwd:         "/tmp",
+			Start:       start.Add(time.Duration(i) * time.Minute),
+			DurationMS:  1500,
+			ExitCode:    i * 3,
+		}
+		if err := log.Append(entry); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	// A line cut short is skipped
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("Failed to open log: %v", err)
+	}
+	f.WriteString(`{"snippet_id": "01A`)
+	f.Close()
+
+	entries, err = log.Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Read() = %d runs, want 2", len(entries))
+	}
+	latest := entries[0]
+	if latest.Number != 1 || latest.Command != "exit 3" || latest.ExitCode != 3 || !latest.Start.Equal(start.Add(time.Minute)) {
+		t.Errorf("Read()[0] = %+v, want run 1 of exit 3", latest)
+	}
+	if latest.Duration() != 1500*time.Millisecond || latest.Vars["n"] != "1" {
+		t.Errorf("Read()[0] duration %v vars %v, want 1.5s and n=1", latest.Duration(), latest.Vars)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("log mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	if got, err := log.Get(2); err != nil || got.Command != "echo one" {
+		t.Errorf("Get(2) = %+v, %v, want echo one", got, err)
+	}
+	if _, err := log.Get(3); err == nil {
+		t.Error("Get(3) succeeded, want an error")
+	}
+}
@@ -0,0 +1,67 @@
+package runner
+
+import "regexp"
+
+// Risk is a dangerous command found in a snippet body
+type Risk struct {
+	Reason string // what the command does
+	Match  string // the text that matched
+}
+
+// riskRule flags the commands matching a pattern
+type riskRule struct {
+	reason  string
+	pattern *regexp.Regexp
+}
+
+// riskRules are the built-in patterns of commands that destroy data or run
+// code from the network
+var riskRules = []riskRule{
+	{
+		reason:  "recursive delete of /, ~ or *",
+		pattern: regexp.MustCompile(`\brm\s+(?:-{1,2}[\w-]+\s+)*(?:-[a-zA-Z]*[rR][a-zA-Z]*|--recursive)(?:\s+-{1,2}[\w-]+)*\s+(?:--\s+)?(?:/\*?|~/?\*?|\$HOME/?\*?|\*)(?:[\s;&|)]|$)`),
+	},
+	{
+		reason:  "delete of / without its safeguard",
+		pattern: regexp.MustCompile(`\brm\b[^\n;&|]*--no-preserve-root`),
+	},
+	{
+		reason:  "raw write to a device",
+		pattern: regexp.MustCompile(`\bdd\b[^\n;&|]*\bof=/dev/\w+`),
+	},
+	{
+		reason:  "raw write to a device",
+		pattern: regexp.MustCompile(`>\s*/dev/(?:sd|hd|nvme|disk|mmcblk|xvd|vd)\w*`),
+	},
+	{
+		reason:  "file system creation",
+		pattern: regexp.MustCompile(`\bmkfs(?:\.\w+)?\s`),
+	},
+	{
+		reason:  "download piped into a shell",
+		pattern: regexp.MustCompile(`\b(?:curl|wget|fetch)\b[^\n;&]*\|\s*(?:sudo\s+(?:-\S+\s+)*)?(?:env\s+)?(?:sh|bash|zsh|dash|ksh|fish|python3?|perl|ruby)\b`),
+	},
+	{
+		reason:  "SQL drop or truncate",
+		pattern: regexp.MustCompile(`(?i)\b(?:drop\s+(?:table|database|schema)|truncate\s+table)\b`),
+	},
+	{
+		reason:  "git force push",
+		pattern: regexp.MustCompile(`\bgit\s+(?:-\S+\s+)*push\b[^\n;&|]*\s(?:--force(?:-with-lease)?\b|-[a-zA-Z]*f[a-zA-Z]*\b|\+\S)`),
+	},
+	{
+		reason:  "fork bomb",
+		pattern: regexp.MustCompile(`:\(\)\s*\{\s*:\s*\|\s*:\s*&\s*\}\s*;\s*:`),
+	},
+}
+
+// Risks returns the dangerous commands the built-in rules find in body
+func Risks(body string) []Risk {
+	var risks []Risk
+	for _, rule := range riskRules {
+		for _, match := range rule.pattern.FindAllString(body, -1) {
+			risks = append(risks, Risk{Reason: rule.reason, Match: match})
+		}
+	}
+	return risks
+}
@@ -0,0 +1,41 @@
+package runner
+
+import "testing"
+
+func TestRisks(t *testing.T) {
+	tests := []struct {
+		body string
+		want string // reason of the only risk, empty for none
+	}{
+		{body: "rm -rf /", want: "recursive delete of /, ~ or *"},
+		{body: "sudo rm -r -f ~/ && echo done", want: "recursive delete of /, ~ or *"},
+		{body: "rm -fr *", want: "recursive delete of /, ~ or *"},
+		{body: "rm -rf ./build /tmp/cache", want: ""},
+		{body: "rm -f /tmp/x", want: ""},
+		{body: "dd if=image.iso of=/dev/sdb bs=4M", want: "raw write to a device"},
+		{body: "dd if=/dev/zero of=disk.img", want: ""},
+		{body: "curl -fsSL https://example.com/install.sh | sudo bash", want: "download piped into a shell"},
+		{body: "curl -s https://example.com/data.json | jq .", want: ""},
+		{body: "psql -c 'DROP TABLE users;'", want: "SQL drop or truncate"},
+		{body: "git push --force origin main", want: "git force push"},
+		{body: "git push -uf origin main", want: "git force push"},
+		{body: "git push origin +main", want: "git force push"},
+		{body: "git push --follow-tags origin feature-fix", want: ""},
+		{body: "mkfs.ext4 /dev/sdb1", want: "file system creation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			got := Risks(tt.body)
+			if tt.want == "" {
+				if len(got) != 0 {
+					t.Errorf("Risks() = %+v, want none", got)
+				}
+				return
+			}
+			if len(got) != 1 || got[0].Reason != tt.want {
+				t.Errorf("Risks() = %+v, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,418 @@
+// Package runner runs snippet bodies as programs, with an interpreter picked
+// from the snippet's language
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"snipgo/internal/core"
+)
+
+// Frontmatter keys read by OptionsFromSnippet
+const (
+	KeyWorkdir     = "workdir"
+	KeyEnv         = "env"
+	KeyTimeout     = "timeout"
+	KeyInterpreter = "interpreter"
+	KeyConfirm     = "confirm"
+)
+
+// ExitTimeout is the exit code of a run stopped by its timeout, as with
+// timeout(1)
+const ExitTimeout = 124
+
+// killDelay is how long a process stopped by a timeout has to exit after
+// SIGTERM before it is killed
+const killDelay = 5 * time.Second
+
+// interpreter runs a language: a command line, given the file holding the
+// body, and the extension the file needs
+type interpreter struct {
+	command string
+	ext     string
+}
+
+// builtinInterpreters maps languages, lowercase, to their interpreter.
+// Snippets without a language are run by sh, as before languages mattered,
+// and so are those in a language with no interpreter, such as yaml or text.
+var builtinInterpreters = map[string]interpreter{
+	"":           {"sh", ".sh"},
+	"sh":         {"sh", ".sh"},
+	"shell":      {"sh", ".sh"},
+	"bash":       {"bash", ".sh"},
+	"zsh":        {"zsh", ".zsh"},
+	"fish":       {"fish", ".fish"},
+	"python":     {"python3", ".py"},
+	"python3":    {"python3", ".py"},
+	"py":         {"python3", ".py"},
+	"javascript": {"node", ".js"},
+	"js":         {"node", ".js"},
+	"node":       {"node", ".js"},
+	"ruby":       {"ruby", ".rb"},
+	"rb":         {"ruby", ".rb"},
+	"perl":       {"perl", ".pl"},
+	"go":         {"go run", ".go"},
+	"golang":     {"go run", ".go"},
+	"powershell": {"pwsh -NoProfile -File", ".ps1"},
+	"pwsh":       {"pwsh -NoProfile -File", ".ps1"},
+}
+
+// Options are the settings of a run besides the body and its language
+type Options struct {
+	Interpreter string        // command line overriding the language's interpreter
+	Dir         string        // working directory, the current one if empty
+	Env         []string      // KEY=value pairs added to the environment
+	Timeout     time.Duration // no timeout if zero
+	Confirm     bool          // always ask before running
+}
+
+// OptionsFromSnippet reads the run options set in a snippet's frontmatter:
+//
+//	workdir: ~/src/app
+//	env:
+//	  AWS_PROFILE: prod
+//	timeout: 30s
+//	interpreter: python3 -u
+//	confirm: true
+//
+// env may also be a list of KEY=value strings, and timeout a number of
+// seconds. workdir has ~ and environment variables expanded.
+func OptionsFromSnippet(snippet *core.Snippet) (Options, error) {
+	var opts Options
+
+	if v, ok := snippet.Extra[KeyWorkdir]; ok {
+		dir, ok := v.(string)
+		if !ok {
+			return opts, fmt.Errorf("invalid %s %v: must be a path", KeyWorkdir, v)
+		}
+		opts.Dir = ExpandPath(dir)
+	}
+
+	switch v := snippet.Extra[KeyEnv].(type) {
+	case nil:
+	case map[string]any:
+		for key, value := range v {
+			opts.Env = append(opts.Env, key+"="+fmt.Sprint(value))
+		}
+		sort.Strings(opts.Env)
+	case []any:
+		for _, item := range v {
+			pair, ok := item.(string)
+			if !ok || !strings.Contains(pair, "=") {
+				return opts, fmt.Errorf("invalid %s entry %v: expected KEY=value", KeyEnv, item)
+			}
+			opts.Env = append(opts.Env, pair)
+		}
+	default:
+		return opts, fmt.Errorf("invalid %s: expected a mapping or a list of KEY=value", KeyEnv)
+	}
+
+	switch v := snippet.Extra[KeyTimeout].(type) {
+	case nil:
+	case int:
+		opts.Timeout = time.Duration(v) * time.Second
+	case float64:
+		opts.Timeout = time.Duration(v * float64(time.Second))
+	case string:
+		timeout, err := ParseTimeout(v)
+		if err != nil {
+			return opts, err
+		}
+		opts.Timeout = timeout
+	default:
+		return opts, fmt.Errorf("invalid %s %v: expected a duration such as 30s", KeyTimeout, v)
+	}
+
+	if v, ok := snippet.Extra[KeyInterpreter]; ok {
+		command, ok := v.(string)
+		if !ok {
+			return opts, fmt.Errorf("invalid %s %v: must be a command line", KeyInterpreter, v)
+		}
+		opts.Interpreter = command
+	}
+
+	if v, ok := snippet.Extra[KeyConfirm]; ok {
+		confirm, ok := v.(bool)
+		if !ok {
+			return opts, fmt.Errorf("invalid %s %v: must be true or false", KeyConfirm, v)
+		}
+		opts.Confirm = confirm
+	}
+
+	return opts, nil
+}
+
+// ParseTimeout parses a timeout, a duration such as 1m30s or a number of
+// seconds
+func ParseTimeout(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	timeout, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: expected a duration such as 30s", KeyTimeout, s)
+	}
+	return timeout, nil
+}
+
+// ExpandPath expands a leading ~ and environment variables in a path
+func ExpandPath(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(homeDir, path[1:])
+		}
+	}
+	return os.ExpandEnv(path)
+}
+
+// Command is a snippet body ready to run. Close removes the temporary file
+// holding the body.
+type Command struct {
+	Interpreter string // command line the body is run with
+	Args        []string
+	Dir         string
+	Env         []string // added to the environment of snipgo
+	Timeout     time.Duration
+
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	file string
+}
+
+// Result describes a finished run
+type Result struct {
+	ExitCode int
+	TimedOut bool
+	Duration time.Duration
+}
+
+// Prepare writes body to a temporary file and returns the command running
+// it with the interpreter of language, sh if it has none. interpreters maps
+// languages to command lines overriding the built-in ones; opts.Interpreter
+// overrides both. In a command line, {file} stands for the file, which is
+// otherwise the last argument.
+func Prepare(body, language string, interpreters map[string]string, opts Options) (*Command, error) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	builtin, known := builtinInterpreters[language]
+
+	command := opts.Interpreter
+	if command == "" {
+		command = interpreters[language]
+	}
+	if command == "" {
+		if !known {
+			builtin, known = builtinInterpreters[""], true
+		}
+		command = builtin.command
+	}
+	ext := builtin.ext
+	if !known {
+		ext = "." + language
+	}
+
+	args, err := SplitCommand(command)
+	if err != nil {
+		return nil, fmt.Errorf("invalid interpreter %q: %w", command, err)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty interpreter for language %q", language)
+	}
+
+	f, err := os.CreateTemp("", "snipgo-exec-*"+ext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	if _, err := f.WriteString(body + "\n"); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	placed := false
+	for i, arg := range args {
+		if strings.Contains(arg, "{file}") {
+			args[i] = strings.ReplaceAll(arg, "{file}", f.Name())
+			placed = true
+		}
+	}
+	if !placed {
+		args = append(args, f.Name())
+	}
+
+	return &Command{
+		Interpreter: command,
+		Args:        args,
+		Dir:         opts.Dir,
+		Env:         opts.Env,
+		Timeout:     opts.Timeout,
+		Stdin:       os.Stdin,
+		Stdout:      os.Stdout,
+		Stderr:      os.Stderr,
+		file:        f.Name(),
+	}, nil
+}
+
+// Close removes the temporary file holding the body
+func (c *Command) Close() error {
+	if c.file == "" {
+		return nil
+	}
+	err := os.Remove(c.file)
+	c.file = ""
+	return err
+}
+
+// Run runs the command and waits for it. SIGTERM and SIGHUP sent to snipgo
+// are passed on to it. SIGINT and SIGQUIT, which the terminal sends to the
+// whole foreground process group, are left to the command alone: snipgo
+// keeps running until the command exits. An error is returned only if the
+// command could not be run; its exit code is in the Result.
+func (c *Command) Run(ctx context.Context) (*Result, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, c.Args[0], c.Args[1:]...)
+	cmd.Dir = c.Dir
+	cmd.Env = append(os.Environ(), c.Env...)
+	cmd.Stdin = c.Stdin
+	cmd.Stdout = c.Stdout
+	cmd.Stderr = c.Stderr
+	// Ask the process to stop before killing it
+	cmd.Cancel = func() error {
+		if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = killDelay
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGQUIT, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", c.Args[0], err)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				if sig == syscall.SIGTERM || sig == syscall.SIGHUP {
+					cmd.Process.Signal(sig)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := cmd.Wait()
+	result := &Result{Duration: time.Since(start)}
+	if ctx.Err() == context.DeadlineExceeded {
+		result.TimedOut = true
+		result.ExitCode = ExitTimeout
+		return result, nil
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitCode(exitErr)
+	default:
+		return nil, fmt.Errorf("failed to run %s: %w", c.Args[0], err)
+	}
+	return result, nil
+}
+
+// exitCode returns the exit code of a failed process; a process killed by
+// a signal gets 128 plus the signal number, as in shells
+func exitCode(err *exec.ExitError) int {
+	if code := err.ExitCode(); code >= 0 {
+		return code
+	}
+	if status, ok := err.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return 1
+}
+
+// SplitCommand splits a command line into arguments, honouring single and
+// double quotes and backslash escapes
+func SplitCommand(s string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg := false
+	var quote rune
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\\' && quote != '\'':
+			if i+1 == len(runes) {
+				return nil, errors.New("trailing backslash")
+			}
+			i++
+			arg.WriteRune(runes[i])
+			inArg = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,193 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"snipgo/internal/core"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{input: "python3 -u", want: []string{"python3", "-u"}},
+		{input: `psql -c "select 1" -f {file}`, want: []string{"psql", "-c", "select 1", "-f", "{file}"}},
+		{input: `sh -c 'echo "$0"'`, want: []string{"sh", "-c", `echo "$0"`}},
+		{input: `run a\ b ""`, want: []string{"run", "a b", ""}},
+		{input: "  ", want: nil},
+		{input: `echo "open`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := SplitCommand(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SplitCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOptionsFromSnippet(t *testing.T) {
+	t.Setenv("HOME", "/home/test")
+
+	tests := []struct {
+		name    string
+		extra   map[string]any
+		want    Options
+		wantErr bool
+	}{
+		{name: "none", want: Options{}},
+		{
+			name: "all",
+			extra: map[string]any{
+				"workdir":     "~/src",
+				"env":         map[string]any{"B": 2, "A": "x"},
+				"timeout":     "1m30s",
+				"interpreter": "python3 -u",
+				"confirm":     true,
+			},
+			want: Options{Interpreter: "python3 -u", Dir: "/home/test/src", Env: []string{"A=x", "B=2"}, Timeout: 90 * time.Second, Confirm: true},
+		},
+		{
+			name:  "env list and timeout in seconds",
+			extra: map[string]any{"env": []any{"A=1"}, "timeout": 5},
+			want:  Options{Env: []string{"A=1"}, Timeout: 5 * time.Second},
+		},
+		{name: "invalid env", extra: map[string]any{"env": []any{"A"}}, wantErr: true},
+		{name: "invalid confirm", extra: map[string]any{"confirm": "yes"}, wantErr: true},
+		{name: "invalid timeout", extra: map[string]any{"timeout": "soon"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := OptionsFromSnippet(&core.Snippet{Extra: tt.extra})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OptionsFromSnippet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("OptionsFromSnippet() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	interpreters := map[string]string{"sql": "psql -f {file} -X"}
+
+	tests := []struct {
+		name     string
+		language string
+		opts     Options
+		want     []string // arguments before the file, or with {file} replaced
+		ext      string
+		wantErr  bool
+	}{
+		{name: "no language", language: "", want: []string{"sh", "{file}"}, ext: ".sh"},
+		{name: "python", language: "Python", want: []string{"python3", "{file}"}, ext: ".py"},
+		{name: "go", language: "go", want: []string{"go", "run", "{file}"}, ext: ".go"},
+		{name: "configured", language: "sql", want: []string{"psql", "-f", "{file}", "-X"}, ext: ".sql"},
+		{name: "override", language: "sh", opts: Options{Interpreter: "bash -e"}, want: []string{"bash", "-e", "{file}"}, ext: ".sh"},
+		{name: "no interpreter", language: "yaml", want: []string{"sh", "{file}"}, ext: ".sh"},
+		{name: "unknown with override", language: "cobol", opts: Options{Interpreter: "cobc -x"}, want: []string{"cobc", "-x", "{file}"}, ext: ".cobol"},
+		{name: "empty override", language: "sql", opts: Options{Interpreter: " "}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, err := Prepare("echo hi", tt.language, interpreters, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Prepare() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			defer command.Close()
+
+			if filepath.Ext(command.file) != tt.ext {
+				t.Errorf("Prepare() file = %s, want extension %s", command.file, tt.ext)
+			}
+			want := make([]string, len(tt.want))
+			for i, arg := range tt.want {
+				want[i] = strings.ReplaceAll(arg, "{file}", command.file)
+			}
+			if !reflect.DeepEqual(command.Args, want) {
+				t.Errorf("Prepare() Args = %q, want %q", command.Args, want)
+			}
+
+			data, err := os.ReadFile(command.file)
+			if err != nil || string(data) != "echo hi\n" {
+				t.Errorf("Prepare() file content = %q (%v), want %q", data, err, "echo hi\n")
+			}
+
+			file := command.file
+			if err := command.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			if _, err := os.Stat(file); !os.IsNotExist(err) {
+				t.Errorf("Close() left %s behind", file)
+			}
+		})
+	}
+}
+
+func TestCommand_Run(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name         string
+		body         string
+		opts         Options
+		wantCode     int
+		wantTimedOut bool
+		wantOutput   string
+	}{
+		{name: "success", body: "echo ok", wantOutput: "ok\n"},
+		{name: "exit code", body: "exit 3", wantCode: 3},
+		{name: "signal", body: "kill -TERM $$", wantCode: 128 + 15},
+		{
+			name:       "workdir and env",
+			body:       `echo "$(pwd) $GREETING"`,
+			opts:       Options{Dir: dir, Env: []string{"GREETING=hello"}},
+			wantOutput: dir + " hello\n",
+		},
+		{name: "timeout", body: "exec sleep 5", opts: Options{Timeout: 100 * time.Millisecond}, wantCode: ExitTimeout, wantTimedOut: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command, err := Prepare(tt.body, "sh", nil, tt.opts)
+			if err != nil {
+				t.Fatalf("Prepare() error = %v", err)
+			}
+			defer command.Close()
+
+			var stdout bytes.Buffer
+			command.Stdin = nil
+			command.Stdout = &stdout
+
+			result, err := command.Run(context.Background())
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if result.ExitCode != tt.wantCode || result.TimedOut != tt.wantTimedOut {
+				t.Errorf("Run() = code %d, timed out %v, want code %d, timed out %v", result.ExitCode, result.TimedOut, tt.wantCode, tt.wantTimedOut)
+			}
+			if tt.wantOutput != "" && stdout.String() != tt.wantOutput {
+				t.Errorf("Run() output = %q, want %q", stdout.String(), tt.wantOutput)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+// Package shellhist reads the command history files of bash, zsh and fish
+package shellhist
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported shells
+const (
+	Bash = "bash"
+	Zsh  = "zsh"
+	Fish = "fish"
+)
+
+// Entry is a command from a history file
+type Entry struct {
+	Command string
+	Time    time.Time // zero if the history doesn't record it
+}
+
+// DetectShell returns the user's shell from $SHELL, or Bash if it isn't a
+// supported one
+func DetectShell() string {
+	switch shell := filepath.Base(os.Getenv("SHELL")); shell {
+	case Zsh, Fish:
+		return shell
+	default:
+		return Bash
+	}
+}
+
+// DefaultFile returns the history file of a shell: $HISTFILE for bash and
+// zsh if set, otherwise the shell's default location
+func DefaultFile(shell string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	switch shell {
+	case Bash, Zsh:
+		if path := os.Getenv("HISTFILE"); path != "" && DetectShell() == shell {
+			return path, nil
+		}
+		if shell == Bash {
+			return filepath.Join(homeDir, ".bash_history"), nil
+		}
+		dir := os.Getenv("ZDOTDIR")
+		if dir == "" {
+			dir = homeDir
+		}
+		return filepath.Join(dir, ".zsh_history"), nil
+	case Fish:
+		dataDir := os.Getenv("XDG_DATA_HOME")
+		if dataDir == "" {
+			dataDir = filepath.Join(homeDir, ".local", "share")
+		}
+		return filepath.Join(dataDir, "fish", "fish_history"), nil
+	default:
+		return "", fmt.Errorf("unsupported shell %q (expected bash, zsh or fish)", shell)
+	}
+}
+
+// ReadFile reads the history file of a shell, oldest entry first
+func ReadFile(shell, path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer f.Close()
+
+	entries, err := Parse(shell, f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// Parse reads history in the format of a shell, oldest entry first
+func Parse(shell string, r io.Reader) ([]Entry, error) {
+	switch shell {
+	case Bash:
+		return parseBash(r)
+	case Zsh:
+		return parseZsh(r)
+	case Fish:
+		return parseFish(r)
+	default:
+		return nil, fmt.Errorf("unsupported shell %q (expected bash, zsh or fish)", shell)
+	}
+}
+
+// Recent returns the last n distinct commands of entries, most recent first.
+// A command run several times is placed at its last run.
+func Recent(entries []Entry, n int) []Entry {
+	seen := make(map[string]bool)
+	var recent []Entry
+	for i := len(entries) - 1; i >= 0 && len(recent) < n; i-- {
+		command := strings.TrimSpace(entries[i].Command)
+		if command == "" || seen[command] {
+			continue
+		}
+		seen[command] = true
+		recent = append(recent, Entry{Command: command, Time: entries[i].Time})
+	}
+	return recent
+}
+
+// newScanner returns a line scanner that accepts long history lines
+func newScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	return scanner
+}
+
+// parseBash reads a bash history file: one command per line, each preceded
+// by a "#<unix time>" comment if HISTTIMEFORMAT was set
+func parseBash(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	var when time.Time
+	scanner := newScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			if sec, err := strconv.ParseInt(line[1:], 10, 64); err == nil {
+				when = time.Unix(sec, 0)
+				continue
+			}
+		}
+		entries = append(entries, Entry{Command: line, Time: when})
+		when = time.Time{}
+	}
+	return entries, scanner.Err()
+}
+
+// zshMeta marks a metafied byte in zsh history: the next byte is the
+// original one XOR 32
+const zshMeta = 0x83
+
+// parseZsh reads a zsh history file, in the plain or the extended format
+// (": <start>:<elapsed>;<command>"). Newlines within a command are stored as
+// a backslash at the end of the line.
+func parseZsh(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	var command []byte
+	scanner := newScanner(r)
+	for scanner.Scan() {
+		line := unmetafy(scanner.Bytes())
+		if bytes.HasSuffix(line, []byte(`\`)) {
+			command = append(command, line[:len(line)-1]...)
+			command = append(command, '\n')
+			continue
+		}
+		command = append(command, line...)
+		entries = append(entries, parseZshEntry(string(command)))
+		command = command[:0]
+	}
+	if len(command) > 0 {
+		entries = append(entries, parseZshEntry(strings.TrimSuffix(string(command), "\n")))
+	}
+	return entries, scanner.Err()
+}
+
+// parseZshEntry parses a zsh history entry, extended or not
+func parseZshEntry(line string) Entry {
+	if !strings.HasPrefix(line, ": ") {
+		return Entry{Command: line}
+	}
+	meta, command, ok := strings.Cut(line[2:], ";")
+	if !ok {
+		return Entry{Command: line}
+	}
+	start, _, _ := strings.Cut(meta, ":")
+	sec, err := strconv.ParseInt(start, 10, 64)
+	if err != nil {
+		return Entry{Command: line}
+	}
+	return Entry{Command: command, Time: time.Unix(sec, 0)}
+}
+
+// unmetafy decodes the bytes zsh metafies in its history file
+func unmetafy(line []byte) []byte {
+	if bytes.IndexByte(line, zshMeta) < 0 {
+		return line
+	}
+	out := make([]byte, 0, len(line))
+	for i := 0; i < len(line); i++ {
+		if line[i] == zshMeta && i+1 < len(line) {
+			i++
+			out = append(out, line[i]^32)
+			continue
+		}
+		out = append(out, line[i])
+	}
+	return out
+}
+
+// parseFish reads a fish history file, a YAML-like list of entries:
+//
+//	# fish_history
+//	- cmd: git status
+//	  when: 1700000000
+//	  paths:
+//	    - README.md
+//
+// Backslashes and newlines in commands are escaped as \\ and \n.
+func parseFish(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+	unescape := strings.NewReplacer(`\\`, `\`, `\n`, "\n")
+	scanner := newScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if command, ok := strings.CutPrefix(line, "- cmd: "); ok {
+			entries = append(entries, Entry{Command: unescape.Replace(command)})
+			continue
+		}
+		if when, ok := strings.CutPrefix(line, "  when: "); ok && len(entries) > 0 {
+			if sec, err := strconv.ParseInt(strings.TrimSpace(when), 10, 64); err == nil {
+				entries[len(entries)-1].Time = time.Unix(sec, 0)
+			}
+		}
+	}
+	return entries, scanner.Err()
+}
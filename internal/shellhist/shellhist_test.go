@@ -0,0 +1,100 @@
+package shellhist
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		shell   string
+		history string
+		want    []Entry
+	}{
+		{
+			name:    "bash",
+			shell:   Bash,
+			history: "ls -la\n#1700000000\ngit status\n# a comment\n",
+			want: []Entry{
+				{Command: "ls -la"},
+				{Command: "git status", Time: time.Unix(1700000000, 0)},
+				{Command: "# a comment"},
+			},
+		},
+		{
+			name:    "zsh plain",
+			shell:   Zsh,
+			history: "ls -la\ngit status\n",
+			want:    []Entry{{Command: "ls -la"}, {Command: "git status"}},
+		},
+		{
+			name:    "zsh extended",
+			shell:   Zsh,
+			history: ": 1700000000:0;echo a;b\n: 1700000060:3;for f in *; do\\\n  echo $f\\\ndone\n",
+			want: []Entry{
+				{Command: "echo a;b", Time: time.Unix(1700000000, 0)},
+				{Command: "for f in *; do\n  echo $f\ndone", Time: time.Unix(1700000060, 0)},
+			},
+		},
+		{
+			name:    "zsh metafied",
+			shell:   Zsh,
+			history: "echo \xe3\x83\xa3\xa1\n", // "メ" is e3 83 a1
+			want:    []Entry{{Command: "echo メ"}},
+		},
+		{
+			name:  "fish",
+			shell: Fish,
+			history: "- cmd: git status\n  when: 1700000000\n  paths:\n    - README.md\n" +
+				"- cmd: echo a\\nb \\\\n\n  when: 1700000060\n",
+			want: []Entry{
+				{Command: "git status", Time: time.Unix(1700000000, 0)},
+				{Command: "echo a\nb \\n", Time: time.Unix(1700000060, 0)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.shell, strings.NewReader(tt.history))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Parse() = %q, want %q", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i].Command != tt.want[i].Command || !got[i].Time.Equal(tt.want[i].Time) {
+					t.Errorf("Parse()[%d] = %q at %v, want %q at %v", i, got[i].Command, got[i].Time, tt.want[i].Command, tt.want[i].Time)
+				}
+			}
+		})
+	}
+}
+
+func TestRecent(t *testing.T) {
+	entries := []Entry{
+		{Command: "ls"},
+		{Command: "git status"},
+		{Command: "  "},
+		{Command: "ls"},
+		{Command: "make test"},
+	}
+
+	got := Recent(entries, 2)
+	want := []string{"make test", "ls"}
+	if len(got) != len(want) {
+		t.Fatalf("Recent() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i].Command != want[i] {
+			t.Errorf("Recent()[%d] = %q, want %q", i, got[i].Command, want[i])
+		}
+	}
+
+	if got := Recent(entries, 10); len(got) != 3 {
+		t.Errorf("Recent(10) = %d entries, want 3", len(got))
+	}
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Backend is the storage abstraction used by core.Manager.
+// Paths are absolute paths rooted at GetSnippetsDir.
+type Backend interface {
+	// GetSnippetsDir returns the root directory of the store
+	GetSnippetsDir() string
+	// ListFiles returns all .md files in the store, skipping hidden
+	// directories such as .trash
+	ListFiles() ([]string, error)
+	// ListDir returns all files below dir, which may be hidden. A missing
+	// dir holds no files.
+	ListDir(dir string) ([]string, error)
+	// ReadFile reads the content of a file
+	ReadFile(path string) ([]byte, error)
+	// WriteFile creates or replaces a file
+	WriteFile(path string, data []byte) error
+	// MakeDir creates dir and any missing parents
+	MakeDir(dir string) error
+	// DeleteFile deletes a file
+	DeleteFile(path string) error
+	// RemoveAll deletes path and everything below it. A missing path is
+	// not an error.
+	RemoveAll(path string) error
+	// Stat returns metadata for a file. The error wraps os.ErrNotExist
+	// if the file does not exist.
+	Stat(path string) (*FileInfo, error)
+	// FileExists checks if a file exists
+	FileExists(path string) bool
+	// Lock acquires an exclusive lock on the store for a read-modify-write
+	// sequence and returns a function that releases it
+	Lock() (unlock func(), err error)
+	// Watch reports changes to files in the store outside hidden
+	// directories until ctx is cancelled, at which point the returned
+	// channel is closed
+	Watch(ctx context.Context) (<-chan Event, error)
+}
+
+// FileInfo describes a file in a Backend
+type FileInfo struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// EventOp describes what happened to a file
+type EventOp int
+
+const (
+	EventCreate EventOp = iota
+	EventWrite
+	EventRemove
+	EventRename
+)
+
+// String returns a human-readable name for the operation
+func (op EventOp) String() string {
+	switch op {
+	case EventCreate:
+		return "create"
+	case EventWrite:
+		return "write"
+	case EventRemove:
+		return "remove"
+	case EventRename:
+		return "rename"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is a change notification emitted by Backend.Watch
+type Event struct {
+	Path string
+	Op   EventOp
+}
+
+// inHiddenDir reports whether path lies in a directory below root whose
+// name starts with a dot, such as .trash or .git
+func inHiddenDir(root, path string) bool {
+	rel, err := filepath.Rel(root, filepath.Dir(path))
+	if err != nil || rel == "." {
+		return false
+	}
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		if strings.HasPrefix(part, ".") && part != ".." {
+			return true
+		}
+	}
+	return false
+}
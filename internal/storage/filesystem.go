@@ -1,27 +1,43 @@
 package storage
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"snipgo/internal/config"
+
+	"github.com/fsnotify/fsnotify"
 )
 
+// LockFileName is the name of the advisory lock file in the snippets directory
+const LockFileName = ".snipgo.lock"
+
+// LockTimeout is how long Lock waits for another process to release the lock
+var LockTimeout = 10 * time.Second
+
 // FileSystem handles file system operations for snippets
 type FileSystem struct {
 	snippetsDir string
 }
 
-// NewFileSystem creates a new FileSystem instance
+// NewFileSystem creates a new FileSystem instance rooted at the configured data directory
 func NewFileSystem() (*FileSystem, error) {
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	snippetsDir := cfg.DataDirectory
+	return NewFileSystemAt(cfg.DataDirectory)
+}
+
+// NewFileSystemAt creates a new FileSystem instance rooted at snippetsDir
+func NewFileSystemAt(snippetsDir string) (*FileSystem, error) {
 	if err := os.MkdirAll(snippetsDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create snippets directory: %w", err)
 	}
@@ -44,6 +60,9 @@ func (fs *FileSystem) ListFiles() ([]string, error) {
 		if err != nil {
 			return err
 		}
+		if info.IsDir() && path != fs.snippetsDir && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
 		if !info.IsDir() && strings.HasSuffix(strings.ToLower(path), ".md") {
 			files = append(files, path)
 		}
@@ -57,6 +76,30 @@ func (fs *FileSystem) ListFiles() ([]string, error) {
 	return files, nil
 }
 
+// ListDir returns all files below dir
+func (fs *FileSystem) ListDir(dir string) ([]string, error) {
+	var files []string
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in %s: %w", dir, err)
+	}
+
+	return files, nil
+}
+
 // ReadFile reads the content of a file
 func (fs *FileSystem) ReadFile(filepath string) ([]byte, error) {
 	data, err := os.ReadFile(filepath)
@@ -66,14 +109,58 @@ func (fs *FileSystem) ReadFile(filepath string) ([]byte, error) {
 	return data, nil
 }
 
-// WriteFile writes content to a file
-func (fs *FileSystem) WriteFile(filepath string, data []byte) error {
-	if err := os.WriteFile(filepath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write file %s: %w", filepath, err)
+// WriteFile atomically writes content to a file. The data is written to a
+// temporary file in the same directory, synced, and renamed over the
+// target, so readers never observe a partially written snippet.
+func (fs *FileSystem) WriteFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write file %s: %w", path, err)
+	}
+	tmpPath := tmp.Name()
+
+	// Remove the temp file unless it was renamed into place
+	committed := false
+	defer func() {
+		if !committed {
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write file %s: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync file %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", path, err)
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", path, err)
 	}
+	committed = true
+
+	// Persist the rename itself
+	if err := syncDir(dir); err != nil {
+		slog.Debug("failed to sync directory", "path", dir, "error", err)
+	}
+
 	return nil
 }
 
+// Lock acquires the advisory lock file in the snippets directory, which
+// serializes mutations across snipgo processes (CLI and GUI)
+func (fs *FileSystem) Lock() (func(), error) {
+	return lockFile(filepath.Join(fs.snippetsDir, LockFileName), LockTimeout)
+}
+
 // DeleteFile deletes a file
 func (fs *FileSystem) DeleteFile(filepath string) error {
 	if err := os.Remove(filepath); err != nil {
@@ -82,8 +169,123 @@ func (fs *FileSystem) DeleteFile(filepath string) error {
 	return nil
 }
 
+// MakeDir creates dir and any missing parents
+func (fs *FileSystem) MakeDir(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+	return nil
+}
+
+// RemoveAll deletes path and everything below it
+func (fs *FileSystem) RemoveAll(path string) error {
+	if err := os.RemoveAll(path); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	return nil
+}
+
 // FileExists checks if a file exists
 func (fs *FileSystem) FileExists(filepath string) bool {
 	_, err := os.Stat(filepath)
 	return err == nil
 }
+
+// Stat returns metadata for a file
+func (fs *FileSystem) Stat(filepath string) (*FileInfo, error) {
+	info, err := os.Stat(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file %s: %w", filepath, err)
+	}
+	return &FileInfo{
+		Path:    filepath,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}, nil
+}
+
+// Watch reports changes to .md files in the snippets directory and its subdirectories
+func (fs *FileSystem) Watch(ctx context.Context) (<-chan Event, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watcher: %w", err)
+	}
+
+	// fsnotify is not recursive, so every directory is added explicitly
+	err = filepath.Walk(fs.snippetsDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != fs.snippetsDir && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return watcher.Add(path)
+		}
+		return nil
+	})
+	if err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("failed to watch %s: %w", fs.snippetsDir, err)
+	}
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		defer watcher.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				slog.Warn("file watcher error", "error", err)
+			case ev, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				if ev.Has(fsnotify.Create) {
+					if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+						if strings.HasPrefix(info.Name(), ".") {
+							continue
+						}
+						if err := watcher.Add(ev.Name); err != nil {
+							slog.Warn("failed to watch directory", "path", ev.Name, "error", err)
+						}
+						continue
+					}
+				}
+
+				if !strings.HasSuffix(strings.ToLower(ev.Name), ".md") {
+					continue
+				}
+
+				var op EventOp
+				switch {
+				case ev.Has(fsnotify.Create):
+					op = EventCreate
+				case ev.Has(fsnotify.Write):
+					op = EventWrite
+				case ev.Has(fsnotify.Remove):
+					op = EventRemove
+				case ev.Has(fsnotify.Rename):
+					op = EventRename
+				default:
+					continue
+				}
+
+				select {
+				case events <- Event{Path: ev.Name, Op: op}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
@@ -1,9 +1,12 @@
 package storage
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 // setupTestConfig creates a temporary config file and sets SNIPGO_CONFIG_PATH
@@ -168,6 +171,21 @@ func TestFileSystem_ListFiles(t *testing.T) {
 			wantCount: 1,
 			wantErr:   false,
 		},
+		{
+			name: "skip hidden directories",
+			setup: func() error {
+				if err := os.WriteFile(filepath.Join(tmpDir, "test.md"), []byte("content"), 0644); err != nil {
+					return err
+				}
+				trashDir := filepath.Join(tmpDir, ".trash", "entry")
+				if err := os.MkdirAll(trashDir, 0755); err != nil {
+					return err
+				}
+				return os.WriteFile(filepath.Join(trashDir, "deleted.md"), []byte("content"), 0644)
+			},
+			wantCount: 1,
+			wantErr:   false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -196,6 +214,45 @@ func TestFileSystem_ListFiles(t *testing.T) {
 	}
 }
 
+func TestFileSystem_ListDirRemoveAll(t *testing.T) {
+	tmpDir := t.TempDir()
+	fs := &FileSystem{snippetsDir: tmpDir}
+	trashDir := filepath.Join(tmpDir, ".trash")
+
+	files, err := fs.ListDir(trashDir)
+	if err != nil || len(files) != 0 {
+		t.Fatalf("FileSystem.ListDir() of missing dir = %v, %v, want no files", files, err)
+	}
+
+	for _, name := range []string{"a/one.md", "b/sub/two.md"} {
+		path := filepath.Join(trashDir, name)
+		if err := fs.MakeDir(filepath.Dir(path)); err != nil {
+			t.Fatalf("FileSystem.MakeDir() error = %v", err)
+		}
+		if err := fs.WriteFile(filepath.Join(trashDir, name), []byte("content")); err != nil {
+			t.Fatalf("FileSystem.WriteFile() error = %v", err)
+		}
+	}
+
+	files, err = fs.ListDir(trashDir)
+	if err != nil {
+		t.Fatalf("FileSystem.ListDir() error = %v", err)
+	}
+	if len(files) != 2 {
+		t.Errorf("FileSystem.ListDir() = %v, want 2 files", files)
+	}
+
+	if err := fs.RemoveAll(filepath.Join(trashDir, "a")); err != nil {
+		t.Fatalf("FileSystem.RemoveAll() error = %v", err)
+	}
+	if files, _ := fs.ListDir(trashDir); len(files) != 1 {
+		t.Errorf("FileSystem.ListDir() after RemoveAll = %v, want 1 file", files)
+	}
+	if err := fs.RemoveAll(filepath.Join(trashDir, "missing")); err != nil {
+		t.Errorf("FileSystem.RemoveAll() of missing path error = %v", err)
+	}
+}
+
 func TestFileSystem_ReadFile(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "snipgo_test_*")
 	if err != nil {
@@ -449,3 +506,116 @@ func TestFileSystem_FileExists(t *testing.T) {
 	}
 }
 
+
+func TestFileSystem_Stat(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	fs, err := NewFileSystemAt(tmpDir)
+	if err != nil {
+		t.Fatalf("NewFileSystemAt() error = %v", err)
+	}
+
+	path := filepath.Join(tmpDir, "test.md")
+	if err := fs.WriteFile(path, []byte("content")); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	info, err := fs.Stat(path)
+	if err != nil {
+		t.Fatalf("FileSystem.Stat() error = %v", err)
+	}
+	if info.Size != int64(len("content")) {
+		t.Errorf("FileSystem.Stat() Size = %d, want %d", info.Size, len("content"))
+	}
+	if info.ModTime.IsZero() {
+		t.Error("FileSystem.Stat() ModTime is zero")
+	}
+
+	if _, err := fs.Stat(filepath.Join(tmpDir, "missing.md")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("FileSystem.Stat() missing file error = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestFileSystem_Watch(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	fs, err := NewFileSystemAt(tmpDir)
+	if err != nil {
+		t.Fatalf("NewFileSystemAt() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := fs.Watch(ctx)
+	if err != nil {
+		t.Fatalf("FileSystem.Watch() error = %v", err)
+	}
+
+	// Non-markdown files are ignored
+	os.WriteFile(filepath.Join(tmpDir, "ignored.txt"), []byte("x"), 0644)
+	path := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	select {
+	case ev := <-events:
+		if ev.Path != path {
+			t.Errorf("FileSystem.Watch() event path = %s, want %s", ev.Path, path)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("FileSystem.Watch() timed out waiting for event")
+	}
+}
+
+func TestFileSystem_WriteFile_Atomic(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	fs, err := NewFileSystemAt(tmpDir)
+	if err != nil {
+		t.Fatalf("NewFileSystemAt() error = %v", err)
+	}
+
+	path := filepath.Join(tmpDir, "test.md")
+	if err := fs.WriteFile(path, []byte("first")); err != nil {
+		t.Fatalf("FileSystem.WriteFile() error = %v", err)
+	}
+	if err := fs.WriteFile(path, []byte("second")); err != nil {
+		t.Fatalf("FileSystem.WriteFile() overwrite error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(data) != "second" {
+		t.Errorf("FileSystem.WriteFile() content = %q, want %q", data, "second")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("FileSystem.WriteFile() mode = %v, want 0644", info.Mode().Perm())
+	}
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to read dir: %v", err)
+	}
+	if len(entries) != 1 {
+		names := make([]string, 0, len(entries))
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Errorf("FileSystem.WriteFile() left extra files: %v", names)
+	}
+
+	// Writing into a missing directory fails without leaving anything behind
+	if err := fs.WriteFile(filepath.Join(tmpDir, "missing", "test.md"), []byte("x")); err == nil {
+		t.Error("FileSystem.WriteFile() into missing directory error = nil, want error")
+	}
+}
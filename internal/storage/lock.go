@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ErrLockTimeout is returned when the lock file is held by another process for too long
+var ErrLockTimeout = errors.New("timed out waiting for lock")
+
+// lockPollInterval is how often a busy lock is retried
+const lockPollInterval = 50 * time.Millisecond
+
+// lockFile takes an exclusive advisory lock on path, creating it if needed,
+// and retries until timeout if another process holds it
+func lockFile(path string, timeout time.Duration) (func(), error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file %s: %w", path, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLock(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if locked {
+			break
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("%w: %s", ErrLockTimeout, path)
+		}
+		time.Sleep(lockPollInterval)
+	}
+
+	return func() {
+		unlock(f)
+		f.Close()
+	}, nil
+}
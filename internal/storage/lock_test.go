@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LockFileName)
+
+	unlock, err := lockFile(path, time.Second)
+	if err != nil {
+		t.Fatalf("lockFile() error = %v", err)
+	}
+
+	// A second holder (separate open file, as another process would have) must wait
+	if _, err := lockFile(path, 100*time.Millisecond); !errors.Is(err, ErrLockTimeout) {
+		t.Errorf("lockFile() while held error = %v, want ErrLockTimeout", err)
+	}
+
+	// Once released, the lock can be taken by a waiter
+	acquired := make(chan error, 1)
+	go func() {
+		unlock2, err := lockFile(path, 2*time.Second)
+		if err == nil {
+			unlock2()
+		}
+		acquired <- err
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	unlock()
+
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Errorf("lockFile() after release error = %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("lockFile() did not acquire released lock")
+	}
+}
+
+func TestFileSystem_Lock(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	fs, err := NewFileSystemAt(tmpDir)
+	if err != nil {
+		t.Fatalf("NewFileSystemAt() error = %v", err)
+	}
+
+	unlock, err := fs.Lock()
+	if err != nil {
+		t.Fatalf("FileSystem.Lock() error = %v", err)
+	}
+	defer unlock()
+
+	if !fs.FileExists(filepath.Join(tmpDir, LockFileName)) {
+		t.Error("FileSystem.Lock() did not create lock file")
+	}
+
+	// The lock file is not a snippet
+	files, err := fs.ListFiles()
+	if err != nil {
+		t.Fatalf("FileSystem.ListFiles() error = %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("FileSystem.ListFiles() = %v, want no files", files)
+	}
+}
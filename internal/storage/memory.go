@@ -0,0 +1,221 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Memory is an in-memory Backend, useful for tests and embedding
+type Memory struct {
+	root     string
+	files    map[string]memoryFile // key: cleaned path
+	watchers map[chan Event]struct{}
+	mu       sync.RWMutex
+	lock     sync.Mutex // held by Lock callers
+}
+
+type memoryFile struct {
+	data    []byte
+	modTime time.Time
+}
+
+// NewMemory creates an empty in-memory store rooted at root
+func NewMemory(root string) *Memory {
+	return &Memory{
+		root:     filepath.Clean(root),
+		files:    make(map[string]memoryFile),
+		watchers: make(map[chan Event]struct{}),
+	}
+}
+
+// GetSnippetsDir returns the root of the store
+func (m *Memory) GetSnippetsDir() string {
+	return m.root
+}
+
+// ListFiles returns all .md files in the store, sorted by path
+func (m *Memory) ListFiles() ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	files := make([]string, 0, len(m.files))
+	for path := range m.files {
+		if strings.HasSuffix(strings.ToLower(path), ".md") && !inHiddenDir(m.root, path) {
+			files = append(files, path)
+		}
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// ListDir returns all files below dir, sorted by path
+func (m *Memory) ListDir(dir string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	prefix := filepath.Clean(dir) + string(filepath.Separator)
+	var files []string
+	for path := range m.files {
+		if strings.HasPrefix(path, prefix) {
+			files = append(files, path)
+		}
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// ReadFile reads the content of a file
+func (m *Memory) ReadFile(path string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	file, ok := m.files[filepath.Clean(path)]
+	if !ok {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, os.ErrNotExist)
+	}
+
+	data := make([]byte, len(file.data))
+	copy(data, file.data)
+	return data, nil
+}
+
+// WriteFile creates or replaces a file
+func (m *Memory) WriteFile(path string, data []byte) error {
+	path = filepath.Clean(path)
+	stored := make([]byte, len(data))
+	copy(stored, data)
+
+	m.mu.Lock()
+	_, existed := m.files[path]
+	m.files[path] = memoryFile{data: stored, modTime: time.Now()}
+	m.mu.Unlock()
+
+	op := EventCreate
+	if existed {
+		op = EventWrite
+	}
+	m.notify(Event{Path: path, Op: op})
+
+	return nil
+}
+
+// DeleteFile deletes a file
+func (m *Memory) DeleteFile(path string) error {
+	path = filepath.Clean(path)
+
+	m.mu.Lock()
+	if _, ok := m.files[path]; !ok {
+		m.mu.Unlock()
+		return fmt.Errorf("failed to delete file %s: %w", path, os.ErrNotExist)
+	}
+	delete(m.files, path)
+	m.mu.Unlock()
+
+	m.notify(Event{Path: path, Op: EventRemove})
+
+	return nil
+}
+
+// MakeDir does nothing, as directories exist implicitly in memory
+func (m *Memory) MakeDir(dir string) error {
+	return nil
+}
+
+// RemoveAll deletes path and everything below it
+func (m *Memory) RemoveAll(path string) error {
+	path = filepath.Clean(path)
+	prefix := path + string(filepath.Separator)
+
+	m.mu.Lock()
+	var removed []string
+	for p := range m.files {
+		if p == path || strings.HasPrefix(p, prefix) {
+			delete(m.files, p)
+			removed = append(removed, p)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, p := range removed {
+		m.notify(Event{Path: p, Op: EventRemove})
+	}
+
+	return nil
+}
+
+// Stat returns metadata for a file
+func (m *Memory) Stat(path string) (*FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	path = filepath.Clean(path)
+	file, ok := m.files[path]
+	if !ok {
+		return nil, fmt.Errorf("failed to stat file %s: %w", path, os.ErrNotExist)
+	}
+
+	return &FileInfo{
+		Path:    path,
+		Size:    int64(len(file.data)),
+		ModTime: file.modTime,
+	}, nil
+}
+
+// FileExists checks if a file exists
+func (m *Memory) FileExists(path string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, ok := m.files[filepath.Clean(path)]
+	return ok
+}
+
+// Lock acquires an exclusive lock on the store
+func (m *Memory) Lock() (func(), error) {
+	m.lock.Lock()
+	return m.lock.Unlock, nil
+}
+
+// Watch reports writes and deletes made through this store outside hidden directories
+func (m *Memory) Watch(ctx context.Context) (<-chan Event, error) {
+	ch := make(chan Event, 64)
+
+	m.mu.Lock()
+	m.watchers[ch] = struct{}{}
+	m.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		m.mu.Lock()
+		delete(m.watchers, ch)
+		close(ch)
+		m.mu.Unlock()
+	}()
+
+	return ch, nil
+}
+
+// notify delivers an event to all watchers, dropping it for watchers that are not keeping up
+func (m *Memory) notify(ev Event) {
+	if inHiddenDir(m.root, ev.Path) {
+		return
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for ch := range m.watchers {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
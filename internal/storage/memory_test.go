@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMemory_ReadWriteDelete(t *testing.T) {
+	m := NewMemory("/snippets")
+	path := filepath.Join(m.GetSnippetsDir(), "test.md")
+
+	if m.FileExists(path) {
+		t.Fatal("Memory.FileExists() = true for empty store")
+	}
+
+	if err := m.WriteFile(path, []byte("content")); err != nil {
+		t.Fatalf("Memory.WriteFile() error = %v", err)
+	}
+
+	data, err := m.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Memory.ReadFile() error = %v", err)
+	}
+	if string(data) != "content" {
+		t.Errorf("Memory.ReadFile() = %q, want %q", data, "content")
+	}
+
+	// Returned data must not alias the stored copy
+	data[0] = 'X'
+	data, _ = m.ReadFile(path)
+	if string(data) != "content" {
+		t.Errorf("Memory.ReadFile() returned aliased data, got %q", data)
+	}
+
+	info, err := m.Stat(path)
+	if err != nil {
+		t.Fatalf("Memory.Stat() error = %v", err)
+	}
+	if info.Size != int64(len("content")) {
+		t.Errorf("Memory.Stat() Size = %d, want %d", info.Size, len("content"))
+	}
+
+	if err := m.DeleteFile(path); err != nil {
+		t.Fatalf("Memory.DeleteFile() error = %v", err)
+	}
+
+	if _, err := m.ReadFile(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Memory.ReadFile() after delete error = %v, want os.ErrNotExist", err)
+	}
+	if _, err := m.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Memory.Stat() after delete error = %v, want os.ErrNotExist", err)
+	}
+	if err := m.DeleteFile(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Memory.DeleteFile() twice error = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestMemory_ListFiles(t *testing.T) {
+	m := NewMemory("/snippets")
+
+	for _, name := range []string{"b.md", "a.md", "notes.txt", "sub/c.MD", ".trash/x/d.md"} {
+		if err := m.WriteFile(filepath.Join("/snippets", name), []byte("x")); err != nil {
+			t.Fatalf("Memory.WriteFile() error = %v", err)
+		}
+	}
+
+	files, err := m.ListFiles()
+	if err != nil {
+		t.Fatalf("Memory.ListFiles() error = %v", err)
+	}
+
+	want := []string{"/snippets/a.md", "/snippets/b.md", "/snippets/sub/c.MD"}
+	if len(files) != len(want) {
+		t.Fatalf("Memory.ListFiles() = %v, want %v", files, want)
+	}
+	for i := range want {
+		if files[i] != want[i] {
+			t.Errorf("Memory.ListFiles()[%d] = %s, want %s", i, files[i], want[i])
+		}
+	}
+}
+
+func TestMemory_ListDirRemoveAll(t *testing.T) {
+	m := NewMemory("/snippets")
+
+	for _, name := range []string{"a.md", ".trash/x/b.md", ".trash/x/sub/c.md", ".trash/y/d.md"} {
+		if err := m.WriteFile(filepath.Join("/snippets", name), []byte("x")); err != nil {
+			t.Fatalf("Memory.WriteFile() error = %v", err)
+		}
+	}
+
+	files, err := m.ListDir("/snippets/.trash")
+	if err != nil {
+		t.Fatalf("Memory.ListDir() error = %v", err)
+	}
+	want := []string{"/snippets/.trash/x/b.md", "/snippets/.trash/x/sub/c.md", "/snippets/.trash/y/d.md"}
+	if len(files) != len(want) {
+		t.Fatalf("Memory.ListDir() = %v, want %v", files, want)
+	}
+	for i := range want {
+		if files[i] != want[i] {
+			t.Errorf("Memory.ListDir()[%d] = %s, want %s", i, files[i], want[i])
+		}
+	}
+
+	if err := m.RemoveAll("/snippets/.trash/x"); err != nil {
+		t.Fatalf("Memory.RemoveAll() error = %v", err)
+	}
+	if files, _ := m.ListDir("/snippets/.trash"); len(files) != 1 {
+		t.Errorf("Memory.ListDir() after RemoveAll = %v, want 1 file", files)
+	}
+	if !m.FileExists("/snippets/a.md") {
+		t.Error("Memory.RemoveAll() removed a file outside the path")
+	}
+}
+
+func TestMemory_Watch(t *testing.T) {
+	m := NewMemory("/snippets")
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events, err := m.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Memory.Watch() error = %v", err)
+	}
+
+	path := "/snippets/test.md"
+	m.WriteFile(path, []byte("1"))
+	m.WriteFile(path, []byte("2"))
+	m.DeleteFile(path)
+
+	want := []EventOp{EventCreate, EventWrite, EventRemove}
+	for _, op := range want {
+		select {
+		case ev := <-events:
+			if ev.Op != op || ev.Path != path {
+				t.Errorf("Memory.Watch() event = %v %s, want %v %s", ev.Op, ev.Path, op, path)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Memory.Watch() timed out waiting for %v", op)
+		}
+	}
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("Memory.Watch() channel not closed after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Error("Memory.Watch() channel not closed after cancel")
+	}
+}
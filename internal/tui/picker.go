@@ -0,0 +1,441 @@
+// Package tui implements snipgo's interactive terminal picker
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"snipgo/internal/core"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// ErrCancelled is returned when the user leaves the picker without choosing
+var ErrCancelled = errors.New("selection cancelled")
+
+// SearchFunc returns the snippets matching a query, best first
+type SearchFunc func(query string) ([]*core.SearchResult, error)
+
+// Options configure the picker
+type Options struct {
+	Query  string     // initial query
+	Multi  bool       // allow selecting several snippets with Tab
+	Search SearchFunc // runs the query on every edit
+	Output io.Writer  // where the picker is drawn, defaults to the terminal
+}
+
+// Pick runs the picker and returns the chosen snippets. Without
+// multi-selection, or if nothing was marked with Tab, that is the snippet
+// under the cursor.
+func Pick(opts Options) ([]*core.Snippet, error) {
+	out := opts.Output
+	if out == nil {
+		// Draw on the terminal even when stdout or stderr are redirected,
+		// e.g. in BUFFER=$(snipgo search)
+		if tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0); err == nil {
+			defer tty.Close()
+			out = tty
+		} else {
+			out = os.Stderr
+		}
+	}
+
+	m := newModel(opts, newStyles(lipgloss.NewRenderer(out)))
+	final, err := tea.NewProgram(m, tea.WithInputTTY(), tea.WithOutput(out), tea.WithAltScreen()).Run()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run picker: %w", err)
+	}
+	return final.(*model).chosen()
+}
+
+// styles used to draw the picker
+type styles struct {
+	cursor    lipgloss.Style
+	marker    lipgloss.Style
+	match     lipgloss.Style
+	tag       lipgloss.Style
+	dim       lipgloss.Style
+	title     lipgloss.Style
+	errorText lipgloss.Style
+	border    lipgloss.Style
+}
+
+func newStyles(r *lipgloss.Renderer) styles {
+	return styles{
+		cursor:    r.NewStyle().Bold(true).Foreground(lipgloss.Color("12")),
+		marker:    r.NewStyle().Foreground(lipgloss.Color("10")),
+		match:     r.NewStyle().Bold(true).Foreground(lipgloss.Color("11")),
+		tag:       r.NewStyle().Foreground(lipgloss.Color("14")),
+		dim:       r.NewStyle().Foreground(lipgloss.Color("8")),
+		title:     r.NewStyle().Bold(true),
+		errorText: r.NewStyle().Foreground(lipgloss.Color("9")),
+		border:    r.NewStyle().Foreground(lipgloss.Color("8")),
+	}
+}
+
+// model is the bubbletea model of the picker
+type model struct {
+	input   textinput.Model
+	search  SearchFunc
+	query   string // query the results are for
+	results []*core.SearchResult
+	err     error // error of the current query, results are from the last valid one
+
+	cursor int // index into results
+	offset int // first visible result
+
+	multi    bool
+	selected map[string]*core.Snippet // marked snippets by ID
+	order    []string                 // IDs in the order they were marked
+
+	width, height int
+	styles        styles
+	done          bool // the user confirmed a choice
+}
+
+func newModel(opts Options, st styles) *model {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Placeholder = "tag:docker lang:sh \"exact phrase\" -exclude"
+	input.SetValue(opts.Query)
+	input.Focus()
+
+	m := &model{
+		input:    input,
+		search:   opts.Search,
+		multi:    opts.Multi,
+		selected: make(map[string]*core.Snippet),
+		width:    80,
+		height:   24,
+		styles:   st,
+	}
+	m.refresh()
+	return m
+}
+
+// refresh runs the query in the input if it changed
+func (m *model) refresh() {
+	query := m.input.Value()
+	if query == m.query && m.results != nil {
+		return
+	}
+
+	results, err := m.search(query)
+	m.query = query
+	if err != nil {
+		// Keep showing the previous results while the query is incomplete
+		m.err = err
+		return
+	}
+	m.err = nil
+	m.results = results
+	m.cursor, m.offset = 0, 0
+}
+
+func (m *model) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.scroll()
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			return m, tea.Quit
+		case "enter":
+			if len(m.results) > 0 || len(m.selected) > 0 {
+				m.done = true
+				return m, tea.Quit
+			}
+			return m, nil
+		case "up", "ctrl+p", "ctrl+k":
+			m.move(-1)
+			return m, nil
+		case "down", "ctrl+n", "ctrl+j":
+			m.move(1)
+			return m, nil
+		case "pgup":
+			m.move(-m.listHeight())
+			return m, nil
+		case "pgdown":
+			m.move(m.listHeight())
+			return m, nil
+		case "tab":
+			if m.multi {
+				m.toggle()
+				m.move(1)
+			}
+			return m, nil
+		case "shift+tab":
+			if m.multi {
+				m.toggle()
+				m.move(-1)
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	m.refresh()
+	return m, cmd
+}
+
+// move moves the cursor by delta results, staying in range
+func (m *model) move(delta int) {
+	m.cursor = max(0, min(m.cursor+delta, len(m.results)-1))
+	m.scroll()
+}
+
+// scroll keeps the cursor visible
+func (m *model) scroll() {
+	height := m.listHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+height {
+		m.offset = m.cursor - height + 1
+	}
+}
+
+// toggle marks or unmarks the snippet under the cursor
+func (m *model) toggle() {
+	if len(m.results) == 0 {
+		return
+	}
+	snippet := m.results[m.cursor].Snippet
+	if _, ok := m.selected[snippet.ID]; ok {
+		delete(m.selected, snippet.ID)
+		for i, id := range m.order {
+			if id == snippet.ID {
+				m.order = append(m.order[:i], m.order[i+1:]...)
+				break
+			}
+		}
+		return
+	}
+	m.selected[snippet.ID] = snippet
+	m.order = append(m.order, snippet.ID)
+}
+
+// chosen returns the snippets the user picked
+func (m *model) chosen() ([]*core.Snippet, error) {
+	if !m.done {
+		return nil, ErrCancelled
+	}
+	if len(m.order) > 0 {
+		snippets := make([]*core.Snippet, len(m.order))
+		for i, id := range m.order {
+			snippets[i] = m.selected[id]
+		}
+		return snippets, nil
+	}
+	return []*core.Snippet{m.results[m.cursor].Snippet}, nil
+}
+
+// sideBySide reports whether the preview is drawn next to the list rather
+// than below it
+func (m *model) sideBySide() bool {
+	return m.width >= 100
+}
+
+// listHeight is the number of result rows shown
+func (m *model) listHeight() int {
+	// The prompt and status lines take two rows
+	height := m.height - 2
+	if !m.sideBySide() {
+		height /= 2
+	}
+	return max(height, 1)
+}
+
+// listWidth is the width of the result list
+func (m *model) listWidth() int {
+	if m.sideBySide() {
+		return m.width * 2 / 5
+	}
+	return m.width
+}
+
+func (m *model) View() string {
+	var b strings.Builder
+	b.WriteString(ansi.Truncate(m.input.View(), m.width, ""))
+	b.WriteString("\n")
+	b.WriteString(m.statusLine())
+	b.WriteString("\n")
+
+	list := m.listView()
+	if m.sideBySide() {
+		previewWidth := m.width - m.listWidth() - 3
+		preview := m.previewView(previewWidth, m.listHeight())
+		sep := strings.TrimSuffix(strings.Repeat(m.styles.border.Render(" │ ")+"\n", m.listHeight()), "\n")
+		b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top,
+			lipgloss.NewStyle().Width(m.listWidth()).Render(list), sep, preview))
+	} else {
+		b.WriteString(list)
+		b.WriteString("\n")
+		b.WriteString(m.styles.border.Render(strings.Repeat("─", m.width)))
+		b.WriteString("\n")
+		b.WriteString(m.previewView(m.width, m.height-m.listHeight()-3))
+	}
+	return b.String()
+}
+
+// statusLine shows the result count, selection and query errors
+func (m *model) statusLine() string {
+	if m.err != nil {
+		return ansi.Truncate(m.styles.errorText.Render("  "+m.err.Error()), m.width, "…")
+	}
+	status := fmt.Sprintf("  %d results", len(m.results))
+	if len(m.selected) > 0 {
+		status += fmt.Sprintf(" (%d selected)", len(m.selected))
+	}
+	if m.multi {
+		status += "  · Tab to select"
+	}
+	return m.styles.dim.Render(status)
+}
+
+// listView renders the visible results, one per row
+func (m *model) listView() string {
+	width := m.listWidth()
+	height := m.listHeight()
+
+	rows := make([]string, 0, height)
+	for i := m.offset; i < len(m.results) && i < m.offset+height; i++ {
+		result := m.results[i]
+
+		prefix := "  "
+		if i == m.cursor {
+			prefix = m.styles.cursor.Render("▌ ")
+		}
+		if _, ok := m.selected[result.Snippet.ID]; ok {
+			prefix += m.styles.marker.Render("● ")
+		}
+
+		row := prefix + m.highlight(result.Snippet.Title, fieldRanges(result, "title", 0))
+		for j, tag := range result.Snippet.Tags {
+			row += " " + m.styles.tag.Render("#") + m.highlightStyled(tag, fieldRanges(result, "tags", j), m.styles.tag)
+		}
+		if i == m.cursor {
+			row = m.styles.title.Render(row)
+		}
+		rows = append(rows, ansi.Truncate(row, width, "…"))
+	}
+	for len(rows) < height {
+		rows = append(rows, "")
+	}
+	return strings.Join(rows, "\n")
+}
+
+// previewView renders the snippet under the cursor: title, metadata and
+// body, scrolled to the first match
+func (m *model) previewView(width, height int) string {
+	if len(m.results) == 0 || height <= 0 {
+		return ""
+	}
+	result := m.results[m.cursor]
+	s := result.Snippet
+
+	lines := []string{m.styles.title.Render(s.Title)}
+
+	meta := []string{s.ID}
+	if s.Language != "" {
+		meta = append(meta, s.Language)
+	}
+	if s.IsFavorite {
+		meta = append(meta, "★")
+	}
+	if !s.UpdatedAt.IsZero() {
+		meta = append(meta, "updated "+s.UpdatedAt.Local().Format("2006-01-02 15:04"))
+	}
+	lines = append(lines, m.styles.dim.Render(strings.Join(meta, " · ")))
+	if len(s.Tags) > 0 {
+		lines = append(lines, m.styles.tag.Render("#"+strings.Join(s.Tags, " #")))
+	}
+	lines = append(lines, m.styles.border.Render(strings.Repeat("─", max(width, 0))))
+
+	// Body, starting a few lines above the first match
+	bodyLines := m.highlightLines(s.Body, fieldRanges(result, "body", 0))
+	room := height - len(lines)
+	start := 0
+	if result.Excerpt != nil && len(bodyLines) > room {
+		start = max(0, min(result.Excerpt.Line-1-room/3, len(bodyLines)-room))
+	}
+	for i := start; i < len(bodyLines) && len(lines) < height; i++ {
+		lines = append(lines, bodyLines[i])
+	}
+
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, width, "…")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fieldRanges returns the match ranges of a field of a result
+func fieldRanges(result *core.SearchResult, field string, index int) []core.MatchRange {
+	for _, match := range result.Matches {
+		if match.Field == field && match.Index == index {
+			return match.Ranges
+		}
+	}
+	return nil
+}
+
+// highlight renders text with the character ranges in the match style
+func (m *model) highlight(text string, ranges []core.MatchRange) string {
+	return m.highlightStyled(text, ranges, lipgloss.NewStyle())
+}
+
+// highlightStyled renders text in base style with the character ranges in
+// the match style
+func (m *model) highlightStyled(text string, ranges []core.MatchRange, base lipgloss.Style) string {
+	runes := []rune(text)
+	var b strings.Builder
+	last := 0
+	for _, r := range ranges {
+		start, end := min(max(r.Start, last), len(runes)), min(r.End, len(runes))
+		if start >= end {
+			continue
+		}
+		b.WriteString(base.Render(string(runes[last:start])))
+		b.WriteString(m.styles.match.Render(string(runes[start:end])))
+		last = end
+	}
+	b.WriteString(base.Render(string(runes[last:])))
+	return b.String()
+}
+
+// highlightLines splits text into lines and highlights the character ranges,
+// which are relative to the whole text
+func (m *model) highlightLines(text string, ranges []core.MatchRange) []string {
+	var lines []string
+	offset := 0
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\t", "    "), "\n") {
+		n := len([]rune(line))
+		var lineRanges []core.MatchRange
+		for _, r := range ranges {
+			if r.End <= offset || r.Start >= offset+n {
+				continue
+			}
+			lineRanges = append(lineRanges, core.MatchRange{
+				Start: max(r.Start, offset) - offset,
+				End:   min(r.End, offset+n) - offset,
+			})
+		}
+		lines = append(lines, m.highlight(line, lineRanges))
+		offset += n + 1
+	}
+	return lines
+}
@@ -0,0 +1,174 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"snipgo/internal/core"
+	"snipgo/internal/storage"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func newTestModel(t *testing.T, multi bool) *model {
+	t.Helper()
+
+	m, err := core.NewManager(core.WithBackend(storage.NewMemory("/snippets")))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	for _, data := range []struct{ title, tag, body string }{
+		{"Docker prune", "docker", "docker system prune -af"},
+		{"Kubectl logs", "k8s", "kubectl logs -f <pod>"},
+		{"Git undo", "git", "git reset --soft HEAD~1"},
+	} {
+		s := core.NewSnippet(data.title)
+		s.Tags = []string{data.tag}
+		s.Language = "sh"
+		s.Body = data.body
+		if err := m.Save(s); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	search := func(query string) ([]*core.SearchResult, error) {
+		if strings.TrimSpace(query) == "" {
+			var results []*core.SearchResult
+			for _, s := range m.GetAll() {
+				results = append(results, &core.SearchResult{Snippet: s})
+			}
+			return results, nil
+		}
+		return m.SearchQuery(query)
+	}
+	return newModel(Options{Multi: multi, Search: search}, newStyles(lipgloss.DefaultRenderer()))
+}
+
+func typeText(m *model, text string) {
+	for _, r := range text {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+func press(m *model, key tea.KeyType) {
+	m.Update(tea.KeyMsg{Type: key})
+}
+
+func titles(snippets []*core.Snippet) []string {
+	var out []string
+	for _, s := range snippets {
+		out = append(out, s.Title)
+	}
+	return out
+}
+
+func TestModel_FiltersAsYouType(t *testing.T) {
+	m := newTestModel(t, false)
+	if len(m.results) != 3 {
+		t.Fatalf("initial results = %d, want 3", len(m.results))
+	}
+
+	typeText(m, "kube")
+	if len(m.results) != 1 || m.results[0].Snippet.Title != "Kubectl logs" {
+		t.Fatalf("results for %q = %v", "kube", m.results)
+	}
+
+	press(m, tea.KeyEnter)
+	chosen, err := m.chosen()
+	if err != nil {
+		t.Fatalf("chosen() error = %v", err)
+	}
+	if got := titles(chosen); len(got) != 1 || got[0] != "Kubectl logs" {
+		t.Errorf("chosen() = %v, want [Kubectl logs]", got)
+	}
+}
+
+func TestModel_InvalidQueryKeepsResults(t *testing.T) {
+	m := newTestModel(t, false)
+	typeText(m, "tag:docker (")
+
+	if m.err == nil {
+		t.Error("err = nil, want a query error")
+	}
+	if len(m.results) != 1 {
+		t.Errorf("results = %d, want the 1 result of the last valid query", len(m.results))
+	}
+	if !strings.Contains(m.View(), "invalid query") {
+		t.Error("View() does not show the query error")
+	}
+}
+
+func TestModel_MultiSelect(t *testing.T) {
+	m := newTestModel(t, true)
+	first := m.results[0].Snippet.Title
+	third := m.results[2].Snippet.Title
+
+	press(m, tea.KeyTab) // select the first, move to the second
+	press(m, tea.KeyDown)
+	press(m, tea.KeyTab) // select the third
+	press(m, tea.KeyEnter)
+
+	chosen, err := m.chosen()
+	if err != nil {
+		t.Fatalf("chosen() error = %v", err)
+	}
+	got := titles(chosen)
+	if len(got) != 2 || got[0] != first || got[1] != third {
+		t.Errorf("chosen() = %v, want [%s %s]", got, first, third)
+	}
+}
+
+func TestModel_ToggleDeselects(t *testing.T) {
+	m := newTestModel(t, true)
+
+	press(m, tea.KeyTab)      // select the first, move to the second
+	press(m, tea.KeyShiftTab) // select the second, move back to the first
+	press(m, tea.KeyTab)      // deselect the first
+	if len(m.selected) != 1 || len(m.order) != 1 || m.order[0] != m.results[1].Snippet.ID {
+		t.Errorf("selected = %v, want only the second snippet", m.order)
+	}
+}
+
+func TestModel_Cancel(t *testing.T) {
+	m := newTestModel(t, false)
+	press(m, tea.KeyEsc)
+
+	if _, err := m.chosen(); !errors.Is(err, ErrCancelled) {
+		t.Errorf("chosen() error = %v, want ErrCancelled", err)
+	}
+}
+
+func TestModel_CursorStaysInRange(t *testing.T) {
+	m := newTestModel(t, false)
+
+	press(m, tea.KeyUp)
+	if m.cursor != 0 {
+		t.Errorf("cursor after up = %d, want 0", m.cursor)
+	}
+	for i := 0; i < 5; i++ {
+		press(m, tea.KeyDown)
+	}
+	if m.cursor != 2 {
+		t.Errorf("cursor after 5 downs = %d, want 2", m.cursor)
+	}
+
+	typeText(m, "git")
+	if m.cursor != 0 {
+		t.Errorf("cursor after new query = %d, want 0", m.cursor)
+	}
+}
+
+func TestModel_Preview(t *testing.T) {
+	m := newTestModel(t, false)
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 20})
+	typeText(m, "prune")
+
+	view := m.View()
+	for _, want := range []string{"Docker prune", "#docker", "sh", "docker system prune -af", "1 results"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("View() does not contain %q:\n%s", want, view)
+		}
+	}
+}
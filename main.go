@@ -30,6 +30,7 @@ func main() {
 		},
 		BackgroundColour: &options.RGBA{R: 255, G: 255, B: 255, A: 1},
 		OnStartup:        appInstance.OnStartup,
+		OnShutdown:       appInstance.OnShutdown,
 		Bind:             []interface{}{appInstance},
 	})
 